@@ -0,0 +1,200 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+// AggType identifies how a Node combines its incoming values
+type AggType byte
+
+// Constants for AggTypes
+const (
+	SUM AggType = iota
+	PRODUCT
+	MAX
+	MIN
+	MEAN
+	MEDIAN
+	MAXABS
+)
+
+var (
+	AggTypes = []AggType{SUM, PRODUCT, MAX, MIN, MEAN, MEDIAN, MAXABS}
+)
+
+func (a AggType) String() string {
+	switch a {
+	case SUM:
+		return "SUM"
+	case PRODUCT:
+		return "PRODUCT"
+	case MAX:
+		return "MAX"
+	case MIN:
+		return "MIN"
+	case MEAN:
+		return "MEAN"
+	case MEDIAN:
+		return "MEDIAN"
+	case MAXABS:
+		return "MAXABS"
+	}
+	return "UNKNOWN"
+}
+
+// Combines value into the running aggregate of a node which has already seen
+// count values. Median needs every value so they are kept, sorted, in values.
+func aggregateInto[T Float](aggType AggType, agg T, count int, values []T, value T) (T, []T) {
+	if count == 0 && aggType != MEDIAN {
+		return value, values
+	}
+
+	switch aggType {
+	case PRODUCT:
+		return agg * value, values
+	case MAX:
+		if value > agg {
+			return value, values
+		}
+		return agg, values
+	case MIN:
+		if value < agg {
+			return value, values
+		}
+		return agg, values
+	case MEAN:
+		return agg + (value-agg)/T(count+1), values
+	case MEDIAN:
+		values = insertSorted(values, value)
+		return median(values), values
+	case MAXABS:
+		if abs(value) > abs(agg) {
+			return value, values
+		}
+		return agg, values
+	}
+	return agg + value, values
+}
+
+// Inserts value into the sorted slice values
+func insertSorted[T Float](values []T, value T) []T {
+	values = append(values, value)
+	i := len(values) - 1
+	for ; i > 0 && values[i-1] > value; i-- {
+		values[i] = values[i-1]
+	}
+	values[i] = value
+	return values
+}
+
+// Returns the absolute value of x
+func abs[T Float](x T) T {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Returns the median of the sorted slice values
+func median[T Float](values []T) T {
+	m := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[m-1] + values[m]) / 2
+	}
+	return values[m]
+}
+
+// Returns the aggregate of values, as Combine would produce it
+func aggregate(aggType AggType, values []float64) float64 {
+	var agg float64
+	var sorted []float64
+	for i, v := range values {
+		agg, sorted = aggregateInto(aggType, agg, i, sorted, v)
+	}
+	return agg
+}
+
+// Sets slopes[i] to the derivative of the aggregate of values with respect to
+// values[i]. Where the aggregate picks a single value, only the first value
+// picked receives a slope.
+func aggregateSlopes(aggType AggType, values []float64, slopes []float64) {
+	for i := range slopes {
+		slopes[i] = 0
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	switch aggType {
+	case PRODUCT:
+		for i := range values {
+			p := 1.0
+			for j, v := range values {
+				if j != i {
+					p *= v
+				}
+			}
+			slopes[i] = p
+		}
+	case MAX, MIN, MAXABS:
+		agg := aggregate(aggType, values)
+		for i, v := range values {
+			if v == agg {
+				slopes[i] = 1
+				break
+			}
+		}
+	case MEAN:
+		for i := range values {
+			slopes[i] = 1 / float64(len(values))
+		}
+	case MEDIAN:
+		sorted := insertSorted(nil, values[0])
+		for _, v := range values[1:] {
+			sorted = insertSorted(sorted, v)
+		}
+		m := len(sorted) / 2
+		if len(sorted)%2 == 1 {
+			slopeOf(values, slopes, sorted[m], 1)
+		} else {
+			slopeOf(values, slopes, sorted[m-1], 0.5)
+			slopeOf(values, slopes, sorted[m], 0.5)
+		}
+	default:
+		for i := range values {
+			slopes[i] = 1
+		}
+	}
+}
+
+// Adds slope to the first value equal to v which has no slope yet
+func slopeOf(values []float64, slopes []float64, v float64, slope float64) {
+	for i, x := range values {
+		if x == v && slopes[i] == 0 {
+			slopes[i] = slope
+			return
+		}
+	}
+}
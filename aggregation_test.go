@@ -0,0 +1,91 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestAggregation(t *testing.T) {
+	Convey("Subject: Aggregation", t, func() {
+		values := []float64{2, -6, 3, 1}
+		combine := func(aggType AggType, values []float64) float64 {
+			n := NewNodeAgg(DIRECT, HIDDEN, aggType)
+			for _, v := range values {
+				n.Combine(v)
+			}
+			return n.Activate()
+		}
+
+		Convey("A new Node should default to SUM", func() {
+			So(NewNode(SIGMOID, HIDDEN).AggType(), ShouldEqual, SUM)
+		})
+		Convey("SUM should add the values", func() {
+			So(combine(SUM, values), ShouldEqual, 0.0)
+		})
+		Convey("PRODUCT should multiply the values", func() {
+			So(combine(PRODUCT, values), ShouldEqual, -36.0)
+		})
+		Convey("MAX should keep the largest value", func() {
+			So(combine(MAX, values), ShouldEqual, 3.0)
+		})
+		Convey("MIN should keep the smallest value", func() {
+			So(combine(MIN, values), ShouldEqual, -6.0)
+		})
+		Convey("MEAN should average the values", func() {
+			So(combine(MEAN, values), ShouldAlmostEqual, 0.0)
+			So(combine(MEAN, []float64{1, 2, 6}), ShouldAlmostEqual, 3.0)
+		})
+		Convey("MEDIAN should keep the middle value", func() {
+			So(combine(MEDIAN, values), ShouldEqual, 1.5)
+			So(combine(MEDIAN, []float64{5, -1, 2}), ShouldEqual, 2.0)
+		})
+		Convey("MAXABS should keep the value with the largest magnitude", func() {
+			So(combine(MAXABS, values), ShouldEqual, -6.0)
+		})
+		Convey("No values should leave the input at 0", func() {
+			for _, a := range AggTypes {
+				So(combine(a, nil), ShouldEqual, 0.0)
+			}
+		})
+		Convey("Reset should forget the previous values", func() {
+			n := NewNodeAgg(DIRECT, OUTPUT, MEDIAN)
+			n.Combine(10)
+			n.Combine(20)
+			n.Reset()
+			n.Combine(4)
+			So(n.Activate(), ShouldEqual, 4.0)
+		})
+		Convey("INPUT nodes should still replace their value", func() {
+			n := NewNodeAgg(DIRECT, INPUT, PRODUCT)
+			n.Combine(2)
+			n.Combine(3)
+			So(n.Activate(), ShouldEqual, 3.0)
+		})
+	})
+}
@@ -0,0 +1,278 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"math"
+)
+
+// Gradients holds the derivative of a loss with respect to each parameter of a
+// Network
+type Gradients struct {
+	Weights  []float64 // One per connection, in activation order
+	Bias     []float64 // One per node
+	Response []float64 // One per node
+}
+
+// NewGradients returns zeroed Gradients sized for the Network
+func (n *Network) NewGradients() *Gradients {
+	return &Gradients{
+		Weights:  make([]float64, len(n.conns)),
+		Bias:     make([]float64, len(n.nodes)),
+		Response: make([]float64, len(n.nodes)),
+	}
+}
+
+// Reset sets every gradient back to 0
+func (g *Gradients) Reset() {
+	for _, x := range [][]float64{g.Weights, g.Bias, g.Response} {
+		for i := range x {
+			x[i] = 0
+		}
+	}
+}
+
+// Scale multiplies every gradient by f
+func (g *Gradients) Scale(f float64) {
+	for _, x := range [][]float64{g.Weights, g.Bias, g.Response} {
+		for i := range x {
+			x[i] *= f
+		}
+	}
+}
+
+// Loss identifies the function Backprop minimizes
+type Loss byte
+
+// Constants for Losses
+const (
+	SQUARED_ERROR Loss = iota
+	CROSS_ENTROPY
+)
+
+// Backprop activates the Network with inputs and adds the gradient of the squared
+// error loss, 0.5 * sum((output - target)^2), to grads. It returns the loss.
+// Bias and response gradients are only found for hidden and output nodes.
+// The connections must be in activation order, see SortConnections.
+func (n *Network) Backprop(inputs, targets []float64, grads *Gradients) float64 {
+	return n.BackpropLoss(SQUARED_ERROR, inputs, targets, grads)
+}
+
+// BackpropLoss is Backprop for the given Loss. Any scalers attached to the
+// Network are applied to the inputs and targets, so the loss is measured in
+// scaled units, and hidden nodes are dropped as set by SetDropout. With SOFTMAX
+// or LOG_SOFTMAX outputs, CROSS_ENTROPY is -sum(target * log(probability)) and
+// its gradient is taken through the transform in one step. Otherwise
+// CROSS_ENTROPY treats each output as an independent probability, such as from
+// a SIGMOID node.
+func (n *Network) BackpropLoss(loss Loss, inputs, targets []float64, grads *Gradients) float64 {
+	n.dropHidden()
+	defer n.keepHidden()
+
+	raw := make([]float64, n.outputCount)
+	n.forward(inputs, raw)
+	outputs := make([]float64, len(raw))
+	copy(outputs, raw)
+	transformOutputs(n.transform, outputs)
+
+	errs := make([]float64, len(outputs))
+	value := lossGradient(loss, n.transform, outputs, n.scaleTargets(targets), errs)
+
+	n.backward(n.biasCount+n.inputCount, errs, grads)
+	return value
+}
+
+// Smallest probability passed to a logarithm by the cross entropy loss
+const minProbability = 1e-12
+
+// Returns the loss of outputs, which have already been transformed, against
+// targets and sets errs to its derivative with respect to the outputs before
+// the transform
+func lossGradient(loss Loss, transform OutputTransform, outputs, targets, errs []float64) float64 {
+	value := 0.0
+
+	// Cross entropy through a softmax: the gradient is p * sum(t) - t
+	if loss == CROSS_ENTROPY && transform != NO_TRANSFORM {
+		total := 0.0
+		for _, t := range targets {
+			total += t
+		}
+		for i, y := range outputs {
+			logp, p := y, math.Exp(y)
+			if transform == SOFTMAX {
+				p, logp = y, math.Log(math.Max(y, minProbability))
+			}
+			value -= targets[i] * logp
+			errs[i] = p*total - targets[i]
+		}
+		return value
+	}
+
+	// Derivative with respect to the transformed outputs
+	for i, y := range outputs {
+		t := targets[i]
+		if loss == CROSS_ENTROPY {
+			y = math.Min(math.Max(y, minProbability), 1-minProbability)
+			value -= t*math.Log(y) + (1-t)*math.Log(1-y)
+			errs[i] = (y - t) / (y * (1 - y))
+		} else {
+			value += 0.5 * (y - t) * (y - t)
+			errs[i] = y - t
+		}
+	}
+
+	// Back through the transform
+	switch transform {
+	case SOFTMAX:
+		dot := 0.0
+		for i, y := range outputs {
+			dot += errs[i] * y
+		}
+		for i, y := range outputs {
+			errs[i] = y * (errs[i] - dot)
+		}
+	case LOG_SOFTMAX:
+		sum := 0.0
+		for _, e := range errs {
+			sum += e
+		}
+		for i, y := range outputs {
+			errs[i] -= math.Exp(y) * sum
+		}
+	}
+	return value
+}
+
+// Propagates the derivative of the loss with respect to the outputs, starting
+// at nodes[offset], back through the Network and adds the parameter gradients
+// to grads
+func (n *Network) backward(offset int, outputErrs []float64, grads *Gradients) {
+
+	// Note each node's index, activation and incoming connections
+	index := make(map[Node]int, len(n.nodes))
+	acts := make([]float64, len(n.nodes))
+	for i, x := range n.nodes {
+		index[x] = i
+		acts[i] = x.Activate()
+	}
+	incoming := make([][]int, len(n.nodes))
+	for c, conn := range n.conns {
+		if n.modulatory == 0 || !isModulatory(conn) {
+			j := index[conn.To()]
+			incoming[j] = append(incoming[j], c)
+		}
+	}
+
+	// Derivative of the loss with respect to each node's activation
+	dActs := make([]float64, len(n.nodes))
+	for i, e := range outputErrs {
+		dActs[offset+i] = e
+	}
+
+	// Walk the connections backwards. Every connection out of or gated by a
+	// node comes after those into it, so a node's activation derivative is
+	// complete by the time the first of its incoming connections is reached.
+	done := make([]bool, len(n.nodes))
+	var values, slopes []float64
+	finish := func(j int) {
+		done[j] = true
+		x := n.nodes[j]
+
+		// A node kept by dropout has its activation scaled up; a dropped one
+		// passes nothing back
+		delta := 0.0
+		if keep := keepOf(x); keep != 0 {
+			delta = dActs[j] * keep * slope(x.FuncType(), acts[j]/keep)
+		}
+
+		values, slopes = values[:0], slopes[:0]
+		for _, c := range incoming[j] {
+			values = append(values, acts[index[n.conns[c].From()]]*n.conns[c].Weight()*gate(n.conns[c]))
+			slopes = append(slopes, 0)
+		}
+		aggregateSlopes(x.AggType(), values, slopes)
+
+		grads.Bias[j] += delta
+		grads.Response[j] += delta * aggregate(x.AggType(), values)
+		for k, c := range incoming[j] {
+			dValue := delta * x.Response() * slopes[k]
+			i := index[n.conns[c].From()]
+			w, g := n.conns[c].Weight(), 1.0
+			if gater := gaterOf(n.conns[c]); gater != nil {
+				g = acts[index[gater]]
+				dActs[index[gater]] += dValue * acts[i] * w
+			}
+			grads.Weights[c] += dValue * acts[i] * g
+			dActs[i] += dValue * w * g
+		}
+	}
+	for c := len(n.conns) - 1; c >= 0; c-- {
+		if j := index[n.conns[c].To()]; !done[j] {
+			finish(j)
+		}
+	}
+
+	// Nodes with no incoming connections still have a bias and response
+	for j, x := range n.nodes {
+		if !done[j] && (x.NodeType() == HIDDEN || x.NodeType() == OUTPUT) {
+			finish(j)
+		}
+	}
+}
+
+// ApplyGradients takes a step of size rate against the gradients, adjusting the
+// connection weights and the bias and response of the hidden and output nodes
+func (n *Network) ApplyGradients(grads *Gradients, rate float64) {
+	for i, c := range n.conns {
+		c.SetWeight(c.Weight() - rate*grads.Weights[i])
+	}
+	for i, x := range n.nodes {
+		if x.NodeType() == HIDDEN || x.NodeType() == OUTPUT {
+			x.SetBias(x.Bias() - rate*grads.Bias[i])
+			x.SetResponse(x.Response() - rate*grads.Response[i])
+		}
+	}
+}
+
+// Returns the derivative of the activation function at the point where it
+// produced y
+func slope(funcType FuncType, y float64) float64 {
+	switch funcType {
+	case SIGMOID:
+		return y * (1 - y)
+	case STEEPENED_SIGMOID:
+		return 4.9 * y * (1 - y)
+	case RELU:
+		if y > 0 {
+			return 1
+		}
+		return 0
+	case TANH:
+		return 1 - y*y
+	}
+	return 1
+}
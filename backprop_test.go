@@ -0,0 +1,134 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+// Returns the squared error loss of the Network for one sample
+func sampleLoss(net *Network, inputs, targets []float64) float64 {
+	loss := 0.0
+	for i, x := range net.Activate(inputs) {
+		loss += 0.5 * (x - targets[i]) * (x - targets[i])
+	}
+	return loss
+}
+
+// Estimates the derivative of the loss with respect to a parameter by central
+// differences
+func numericSlope(net *Network, inputs, targets []float64, get func() float64, set func(float64)) float64 {
+	const h = 1e-6
+	x := get()
+	set(x + h)
+	up := sampleLoss(net, inputs, targets)
+	set(x - h)
+	down := sampleLoss(net, inputs, targets)
+	set(x)
+	return (up - down) / (2 * h)
+}
+
+func TestBackprop(t *testing.T) {
+	Convey("Subject: Backprop", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+		net, _ := NewLayered(3, []LayerSpec{{Size: 4, Func: TANH}, {Size: 3, Func: SIGMOID, Agg: MEAN}, {Size: 2, Func: STEEPENED_SIGMOID}})
+		for _, x := range net.nodes {
+			if x.NodeType() == HIDDEN || x.NodeType() == OUTPUT {
+				x.SetBias(random.Next() - 0.5)
+				x.SetResponse(random.Next() + 0.5)
+			}
+		}
+		inputs := []float64{0.3, -0.8, 0.5}
+		targets := []float64{0.9, 0.1}
+
+		Convey("Given the gradients of one sample", func() {
+			grads := net.NewGradients()
+			loss := net.Backprop(inputs, targets, grads)
+
+			Convey("The loss should match the outputs", func() {
+				So(loss, ShouldAlmostEqual, sampleLoss(net, inputs, targets))
+			})
+			Convey("Weight gradients should match finite differences", func() {
+				for i, c := range net.conns {
+					So(grads.Weights[i], ShouldAlmostEqual, numericSlope(net, inputs, targets, c.Weight, c.SetWeight), 1e-7)
+				}
+			})
+			Convey("Bias and response gradients should match finite differences", func() {
+				for i, x := range net.nodes {
+					if x.NodeType() != HIDDEN && x.NodeType() != OUTPUT {
+						continue
+					}
+					So(grads.Bias[i], ShouldAlmostEqual, numericSlope(net, inputs, targets, x.Bias, x.SetBias), 1e-7)
+					So(grads.Response[i], ShouldAlmostEqual, numericSlope(net, inputs, targets, x.Response, x.SetResponse), 1e-7)
+				}
+			})
+			Convey("Reset should zero the gradients", func() {
+				grads.Reset()
+				for _, g := range grads.Weights {
+					So(g, ShouldEqual, 0.0)
+				}
+			})
+		})
+
+		Convey("Given other aggregations", func() {
+			for _, agg := range AggTypes {
+				net, _ := NewLayered(3, []LayerSpec{{Size: 3, Func: TANH, Agg: agg}, {Size: 1, Func: SIGMOID}})
+				grads := net.NewGradients()
+				net.Backprop(inputs, targets[:1], grads)
+				for i, c := range net.conns {
+					So(grads.Weights[i], ShouldAlmostEqual, numericSlope(net, inputs, targets[:1], c.Weight, c.SetWeight), 1e-7)
+				}
+			}
+		})
+
+		Convey("Given repeated steps on XOR", func() {
+			xor, _ := NewLayered(2, []LayerSpec{{Size: 4, Func: TANH}, {Size: 1, Func: SIGMOID}})
+			samples := [][]float64{{0, 0, 0}, {0, 1, 1}, {1, 0, 1}, {1, 1, 0}}
+			epoch := func() float64 {
+				grads := xor.NewGradients()
+				loss := 0.0
+				for _, s := range samples {
+					loss += xor.Backprop(s[:2], s[2:], grads)
+				}
+				xor.ApplyGradients(grads, 0.5)
+				return loss
+			}
+			first := epoch()
+			last := first
+			for i := 0; i < 2000; i++ {
+				last = epoch()
+			}
+			So(last, ShouldBeLessThan, first)
+			for _, s := range samples {
+				So(math.Abs(xor.Activate(s[:2])[0]-s[2]), ShouldBeLessThan, 0.2)
+			}
+		})
+	})
+}
@@ -33,6 +33,10 @@ import (
 // Connection interface
 type Connection interface {
 	activate()
+	From() Node
+	To() Node
+	Weight() float64
+	SetWeight(weight float64)
 }
 
 // Implementation of Connection as a private package struct
@@ -57,6 +61,76 @@ func (c *connection) activate() {
 	c.toNode.Combine(c.fromNode.Activate() * c.weight)
 }
 
+// From returns the source Node of the connection
+func (c *connection) From() Node {
+	return c.fromNode
+}
+
+// To returns the target Node of the connection
+func (c *connection) To() Node {
+	return c.toNode
+}
+
+// Weight returns the weight of the connection
+func (c *connection) Weight() float64 {
+	return c.weight
+}
+
+// SetWeight replaces the weight of the connection
+func (c *connection) SetWeight(weight float64) {
+	c.weight = weight
+}
+
 func (c *connection) String() string {
 	return fmt.Sprintf("%v, %v, %v", c.weight, c.fromNode.NodeType(), c.toNode.NodeType())
 }
+
+// Implementation of a gated Connection, whose weight is multiplied by the
+// activation of a third node, the gater
+type gatedConnection struct {
+	connection
+	gater Node
+}
+
+// Implemented by Connections with a gater
+type gatedConn interface {
+	Connection
+	Gater() Node
+}
+
+// Creates a new gated Connection. The gater must be activated before the
+// connection, which SortConnections and the Graph builder take into account.
+func NewGatedConnection(fromNode Node, toNode Node, gater Node, weight float64) *gatedConnection {
+	return &gatedConnection{connection{fromNode, toNode, weight}, gater}
+}
+
+// Activates a gated connection like any other, with the weight multiplied by
+// the activation of the gater
+func (c *gatedConnection) activate() {
+	c.toNode.Combine(c.fromNode.Activate() * c.weight * c.gater.Activate())
+}
+
+// Gater returns the Node whose activation multiplies the weight
+func (c *gatedConnection) Gater() Node {
+	return c.gater
+}
+
+func (c *gatedConnection) String() string {
+	return fmt.Sprintf("%v, %v, %v, gated by %v", c.weight, c.fromNode.NodeType(), c.toNode.NodeType(), c.gater.NodeType())
+}
+
+// Returns the gater of the connection, or nil if it has none
+func gaterOf(c Connection) Node {
+	if g, ok := c.(gatedConn); ok {
+		return g.Gater()
+	}
+	return nil
+}
+
+// Returns the activation of the connection's gater, or 1 if it has none
+func gate(c Connection) float64 {
+	if g := gaterOf(c); g != nil {
+		return g.Activate()
+	}
+	return 1
+}
@@ -27,6 +27,7 @@ SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package neural
 
 import (
+	"encoding/json"
 	. "github.com/smartystreets/goconvey/convey"
 	"testing"
 )
@@ -54,5 +55,82 @@ func TestConnection(t *testing.T) {
 				So(tgt.input, ShouldEqual, 0.25)
 			})
 		})
+		Convey("Given a new gated Connection", func() {
+			gater := NewDirectNode(INPUT)
+			con := NewGatedConnection(src, tgt, gater, 0.5)
+			Convey("Gater should equal the gating Node", func() {
+				So(con.Gater(), ShouldEqual, gater)
+				So(gaterOf(con), ShouldEqual, gater)
+				So(gaterOf(NewConnection(src, tgt, 0.5)), ShouldBeNil)
+			})
+			Convey("Activation should multiply the weight by the gater", func() {
+				tgt.Reset()
+				src.input = 0.5
+				gater.input = 0.4
+				con.activate()
+				So(tgt.input, ShouldEqual, 0.1)
+			})
+		})
+	})
+}
+
+func TestGatedNetwork(t *testing.T) {
+	Convey("Subject: Gated connections in a Network", t, func() {
+		// The hidden node g gates the path from x to y; the gated connection is
+		// declared before the connection into the gater
+		newNet := func() *Network {
+			net, _ := NewGraph().Bias("b").Input("x").Input("z").Hidden("g", SIGMOID).Hidden("h", TANH).Output("y", SIGMOID).
+				Gate("x", "h", "g", 1.5).Connect("h", "y", -0.8).Connect("z", "g", 2).Connect("b", "g", -0.5).Connect("b", "y", 0.2).Build()
+			return net
+		}
+
+		Convey("The gater should be activated before the gated connection", func() {
+			net := newNet()
+			gated := -1
+			for i, c := range net.conns {
+				if gaterOf(c) != nil {
+					gated = i
+				}
+			}
+			for i, c := range net.conns {
+				if c.To() == gaterOf(net.conns[gated]) {
+					So(i, ShouldBeLessThan, gated)
+				}
+			}
+		})
+		Convey("A closed gate should block the connection", func() {
+			net := newNet()
+			open := net.Activate([]float64{1, 5})
+			closed := net.Activate([]float64{1, -5})
+			blocked := net.Activate([]float64{0, -5})
+			So(open[0], ShouldNotEqual, closed[0])
+			So(closed[0], ShouldAlmostEqual, blocked[0], 1e-4)
+		})
+		Convey("Gradients should include the path through the gater", func() {
+			result, err := GradCheck(newNet(), SQUARED_ERROR, Sample{Inputs: []float64{0.7, 0.3}, Targets: []float64{0.9}})
+			So(err, ShouldBeNil)
+			So(result.MaxError, ShouldBeLessThan, 1e-6)
+		})
+		Convey("A gated Network should survive serialization", func() {
+			net := newNet()
+			data, err := json.Marshal(net)
+			So(err, ShouldBeNil)
+			var restored Network
+			So(json.Unmarshal(data, &restored), ShouldBeNil)
+			So(restored.Activate([]float64{0.7, 0.3}), ShouldResemble, net.Activate([]float64{0.7, 0.3}))
+		})
+		Convey("An undeclared gater should be rejected", func() {
+			_, err := NewGraph().Input("x").Output("y", DIRECT).Gate("x", "y", "g", 1).Build()
+			So(err, ShouldNotBeNil)
+		})
+		Convey("A gater which depends on its own gated connection should be a cycle", func() {
+			_, err := NewGraph().Input("x").Hidden("h", TANH).Output("y", DIRECT).
+				Gate("x", "h", "h", 1).Connect("h", "y", 1).Build()
+			So(err, ShouldEqual, ErrCycle)
+		})
+		Convey("Compiling should be refused", func() {
+			_, err := newNet().Compile()
+			So(err, ShouldNotBeNil)
+		})
 	})
 }
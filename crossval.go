@@ -0,0 +1,188 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// TrainFunc trains a Network on a Dataset. Trainer.Train is a TrainFunc.
+type TrainFunc func(*Network, Dataset) error
+
+// Metric scores a trained Network on a Dataset
+type Metric struct {
+	Name  string
+	Score func(*Network, Dataset) (float64, error)
+}
+
+// Metrics for use with CrossValidate
+var (
+	AccuracyMetric = Metric{"accuracy", func(n *Network, ds Dataset) (float64, error) {
+		r, err := n.EvaluateClassification(ds)
+		return r.Accuracy, err
+	}}
+	RMSEMetric = Metric{"rmse", func(n *Network, ds Dataset) (float64, error) {
+		r, err := n.EvaluateRegression(ds)
+		return r.RMSE, err
+	}}
+	MAEMetric = Metric{"mae", func(n *Network, ds Dataset) (float64, error) {
+		r, err := n.EvaluateRegression(ds)
+		return r.MAE, err
+	}}
+	R2Metric = Metric{"r2", func(n *Network, ds Dataset) (float64, error) {
+		r, err := n.EvaluateRegression(ds)
+		return r.R2, err
+	}}
+)
+
+// LossMetric returns a Metric for the mean loss, see Network.Evaluate
+func LossMetric(loss Loss) Metric {
+	return Metric{"loss", func(n *Network, ds Dataset) (float64, error) {
+		return n.Evaluate(ds, loss)
+	}}
+}
+
+// CVOptions control how CrossValidate divides and scores the Dataset
+type CVOptions struct {
+	// Stratified keeps the share of each class, as given by the targets, about
+	// the same in every fold
+	Stratified bool
+
+	// Seed for shuffling the samples before dividing them. Zero keeps the
+	// samples in order.
+	Seed int64
+
+	// Metrics to report. Defaults to the mean squared error loss.
+	Metrics []Metric
+}
+
+// MetricSummary is the result of one Metric over the folds of a cross validation
+type MetricSummary struct {
+	Name  string    `json:"name"`
+	Mean  float64   `json:"mean"`
+	Std   float64   `json:"std"`   // Population standard deviation over the folds
+	Folds []float64 `json:"folds"` // Score on each fold
+}
+
+// CrossValidate divides the Dataset into k folds. For each fold it builds a new
+// Network, trains it on the other folds and scores it on that fold. It returns
+// the mean and standard deviation of each Metric over the folds.
+func CrossValidate(builder func() *Network, train TrainFunc, ds Dataset, k int, opts ...CVOptions) ([]MetricSummary, error) {
+	var o CVOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if k < 2 || k > ds.Len() {
+		return nil, errors.New("neural: cross validation needs at least 2 folds and a sample per fold")
+	}
+	metrics := o.Metrics
+	if len(metrics) == 0 {
+		metrics = []Metric{LossMetric(SQUARED_ERROR)}
+	}
+
+	summaries := make([]MetricSummary, len(metrics))
+	for m := range metrics {
+		summaries[m].Name = metrics[m].Name
+		summaries[m].Folds = make([]float64, k)
+	}
+
+	folds := ds.folds(k, o.Stratified, o.Seed)
+	for f := range folds {
+		var trainIndexes []int
+		for g := range folds {
+			if g != f {
+				trainIndexes = append(trainIndexes, folds[g]...)
+			}
+		}
+
+		n := builder()
+		if err := train(n, ds.Subset(trainIndexes)); err != nil {
+			return nil, err
+		}
+		test := ds.Subset(folds[f])
+		for m := range metrics {
+			score, err := metrics[m].Score(n, test)
+			if err != nil {
+				return nil, err
+			}
+			summaries[m].Folds[f] = score
+		}
+	}
+
+	for m := range summaries {
+		s := &summaries[m]
+		for _, x := range s.Folds {
+			s.Mean += x / float64(k)
+		}
+		for _, x := range s.Folds {
+			s.Std += (x - s.Mean) * (x - s.Mean) / float64(k)
+		}
+		s.Std = math.Sqrt(s.Std)
+	}
+	return summaries, nil
+}
+
+// Returns the indexes of the samples in each of k folds. Plain folds are
+// consecutive runs of samples. Stratified folds are dealt one class at a time so
+// each fold gets its share of every class.
+func (d Dataset) folds(k int, stratified bool, seed int64) [][]int {
+	order := make([]int, d.Len())
+	for i := range order {
+		order[i] = i
+	}
+	if seed != 0 {
+		order = rand.New(rand.NewSource(seed)).Perm(d.Len())
+	}
+
+	folds := make([][]int, k)
+	if !stratified {
+		for f := range folds {
+			folds[f] = order[f*len(order)/k : (f+1)*len(order)/k]
+		}
+		return folds
+	}
+
+	var classes []int
+	byClass := make(map[int][]int)
+	for _, i := range order {
+		c := classOf(d.Targets[i])
+		if _, ok := byClass[c]; !ok {
+			classes = append(classes, c)
+		}
+		byClass[c] = append(byClass[c], i)
+	}
+	next := 0
+	for _, c := range classes {
+		for _, i := range byClass[c] {
+			folds[next] = append(folds[next], i)
+			next = (next + 1) % k
+		}
+	}
+	return folds
+}
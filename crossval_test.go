@@ -0,0 +1,90 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestCrossValidate(t *testing.T) {
+	Convey("Subject: Cross validation", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+		var _ TrainFunc = (&Trainer{}).Train
+
+		// Ten samples of y = x / 2
+		var ds Dataset
+		for i := 0; i < 10; i++ {
+			x := float64(i) / 10
+			ds.Add([]float64{x}, []float64{x / 2})
+		}
+		builder := func() *Network {
+			net, _ := NewLayered(1, []LayerSpec{{Size: 1, Func: DIRECT}})
+			return net
+		}
+		trainer := &Trainer{LearningRate: 0.5, Epochs: 500}
+
+		Convey("Plain folds should be consecutive and cover every sample once", func() {
+			folds := ds.folds(3, false, 0)
+			So(folds, ShouldResemble, [][]int{{0, 1, 2}, {3, 4, 5}, {6, 7, 8, 9}})
+		})
+		Convey("Stratified folds should balance an uneven class", func() {
+			var labelled Dataset
+			for i := 0; i < 10; i++ {
+				labelled.Add([]float64{0}, []float64{float64(i / 6)})
+			}
+			for _, fold := range labelled.folds(2, true, 3) {
+				ones := 0
+				for _, i := range fold {
+					ones += int(labelled.Targets[i][0])
+				}
+				So(ones, ShouldEqual, 2)
+			}
+		})
+		Convey("Each metric should be summarised over the folds", func() {
+			results, err := CrossValidate(builder, trainer.Train, ds, 5, CVOptions{Seed: 1, Metrics: []Metric{RMSEMetric, R2Metric}})
+			So(err, ShouldBeNil)
+			So(len(results), ShouldEqual, 2)
+			So(results[0].Name, ShouldEqual, "rmse")
+			So(len(results[0].Folds), ShouldEqual, 5)
+			So(results[0].Mean, ShouldBeLessThan, 0.01)
+			So(results[0].Std, ShouldBeGreaterThanOrEqualTo, 0)
+		})
+		Convey("The loss should be reported by default", func() {
+			results, err := CrossValidate(builder, trainer.Train, ds, 2)
+			So(err, ShouldBeNil)
+			So(results[0].Name, ShouldEqual, "loss")
+		})
+		Convey("Too few or too many folds should be rejected", func() {
+			_, err := CrossValidate(builder, trainer.Train, ds, 1)
+			So(err, ShouldNotBeNil)
+			_, err = CrossValidate(builder, trainer.Train, ds, 11)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
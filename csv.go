@@ -0,0 +1,148 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVOptions describes how LoadCSV turns the columns of a CSV file into a Dataset
+type CSVOptions struct {
+	Comma  rune // Field delimiter. Zero means ','
+	Header bool // The first line names the columns
+
+	// Columns, by index from 0, which hold the inputs and the targets. If
+	// Features is empty, every column which is not a target is a feature.
+	Features []int
+	Targets  []int
+
+	// Columns, by name, which hold the inputs and the targets. These need a
+	// header and are added to the columns given by index.
+	FeatureNames []string
+	TargetNames  []string
+}
+
+// LoadCSV reads a Dataset from CSV data. Every selected column must hold a number.
+// The error for a bad row gives its line number.
+func LoadCSV(r io.Reader, opts CSVOptions) (Dataset, error) {
+	var ds Dataset
+
+	reader := csv.NewReader(r)
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	features := append([]int(nil), opts.Features...)
+	targets := append([]int(nil), opts.Targets...)
+
+	// Resolve the named columns
+	if opts.Header {
+		header, err := reader.Read()
+		if err != nil {
+			return ds, fmt.Errorf("neural: reading CSV header: %v", err)
+		}
+		columns := make(map[string]int, len(header))
+		for i, name := range header {
+			columns[strings.TrimSpace(name)] = i
+		}
+		for _, names := range []struct {
+			names []string
+			into  *[]int
+		}{{opts.FeatureNames, &features}, {opts.TargetNames, &targets}} {
+			for _, name := range names.names {
+				i, ok := columns[name]
+				if !ok {
+					return ds, fmt.Errorf("neural: CSV has no column %q", name)
+				}
+				*names.into = append(*names.into, i)
+			}
+		}
+	} else if len(opts.FeatureNames) > 0 || len(opts.TargetNames) > 0 {
+		return ds, errors.New("neural: CSV columns can only be named when there is a header")
+	}
+	if len(targets) == 0 {
+		return ds, errors.New("neural: no CSV target columns given")
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Dataset{}, fmt.Errorf("neural: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		// Every other column is a feature when none are given
+		if len(features) == 0 {
+			isTarget := make(map[int]bool, len(targets))
+			for _, i := range targets {
+				isTarget[i] = true
+			}
+			for i := range record {
+				if !isTarget[i] {
+					features = append(features, i)
+				}
+			}
+		}
+
+		inputs, err := parseColumns(record, features, line)
+		if err != nil {
+			return Dataset{}, err
+		}
+		outputs, err := parseColumns(record, targets, line)
+		if err != nil {
+			return Dataset{}, err
+		}
+		ds.Add(inputs, outputs)
+	}
+	return ds, nil
+}
+
+// Returns the numbers in the given columns of a CSV record read from line
+func parseColumns(record []string, columns []int, line int) ([]float64, error) {
+	values := make([]float64, len(columns))
+	for i, c := range columns {
+		if c < 0 || c >= len(record) {
+			return nil, fmt.Errorf("neural: CSV line %d has %d columns, missing column %d", line, len(record), c)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(record[c]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("neural: CSV line %d, column %d: %q is not a number", line, c, record[c])
+		}
+		values[i] = v
+	}
+	return values, nil
+}
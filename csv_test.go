@@ -0,0 +1,79 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"strings"
+	"testing"
+)
+
+func TestCSV(t *testing.T) {
+	Convey("Subject: CSV", t, func() {
+		data := "x1, x2, label, y\n1.5, 2, a, 0\n-3, 4.25, b, 1\n"
+
+		Convey("Columns can be picked by name", func() {
+			ds, err := LoadCSV(strings.NewReader(data), CSVOptions{
+				Header:       true,
+				FeatureNames: []string{"x2", "x1"},
+				TargetNames:  []string{"y"},
+			})
+			So(err, ShouldBeNil)
+			So(ds.Len(), ShouldEqual, 2)
+			So(ds.Inputs[1], ShouldResemble, []float64{4.25, -3})
+			So(ds.Targets[1], ShouldResemble, []float64{1})
+		})
+		Convey("Columns can be picked by index", func() {
+			ds, err := LoadCSV(strings.NewReader("1;2;3\n4;5;6\n"), CSVOptions{Comma: ';', Targets: []int{0}})
+			So(err, ShouldBeNil)
+			So(ds.Inputs[0], ShouldResemble, []float64{2, 3})
+			So(ds.Targets[1], ShouldResemble, []float64{4})
+		})
+		Convey("A bad value should be reported with its line", func() {
+			_, err := LoadCSV(strings.NewReader(data), CSVOptions{Header: true, Features: []int{0, 2}, Targets: []int{3}})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "line 2")
+		})
+		Convey("A short row should be reported with its line", func() {
+			_, err := LoadCSV(strings.NewReader("1,2,3\n4,5,6\n7,8\n"), CSVOptions{Targets: []int{2}})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "line 3")
+		})
+		Convey("An unknown column name should fail", func() {
+			_, err := LoadCSV(strings.NewReader(data), CSVOptions{Header: true, TargetNames: []string{"z"}})
+			So(err, ShouldNotBeNil)
+		})
+		Convey("Names without a header should fail", func() {
+			_, err := LoadCSV(strings.NewReader(data), CSVOptions{TargetNames: []string{"y"}})
+			So(err, ShouldNotBeNil)
+		})
+		Convey("Missing targets should fail", func() {
+			_, err := LoadCSV(strings.NewReader(data), CSVOptions{Header: true})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,230 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"errors"
+)
+
+// Integrator identifies the method used to advance a CTRNN
+type Integrator byte
+
+// Constants for Integrators
+const (
+	EULER Integrator = iota
+	RK4
+)
+
+// State of a Network run as a continuous-time recurrent neural network. Each
+// hidden, output and modulatory node i has a state y[i] which follows
+//
+//	dy[i]/dt = (-y[i] + sum(w * out[j])) / tau[i]
+//
+// where out[j] = f(bias[j] + response[j] * y[j]) for the nodes feeding i, and
+// the input and bias nodes supply their activation as usual.
+type ctrnn struct {
+	nodes []Node       // Nodes of the Network, in order, when the state was built
+	conns []Connection // Connections of the Network, in order, likewise
+
+	index    map[Node]int
+	incoming [][]int // Indexes of the connections into each node
+
+	y                   []float64 // Node states
+	k1, k2, k3, k4, tmp []float64 // Integration scratch space
+	outs, values        []float64
+}
+
+// ErrTimeConstant is returned when a node is given a time constant which is not
+// positive
+var ErrTimeConstant = errors.New("neural: time constant must be positive")
+
+// SetIntegrator chooses the method Advance uses to integrate the CTRNN
+// dynamics. The default is EULER.
+func (n *Network) SetIntegrator(integrator Integrator) {
+	n.integrator = integrator
+}
+
+// ResetState sets the state of every node in the CTRNN back to 0
+func (n *Network) ResetState() {
+	n.ctrnn = nil
+}
+
+// Advance runs the Network as a continuous-time recurrent neural network for
+// steps time steps of size dt with the inputs held constant, and returns the
+// outputs. Node states are kept between calls until ResetState is called.
+// Unlike Activate, the connections may be in any order and may form cycles.
+func (n *Network) Advance(inputs []float64, dt float64, steps int) (outputs []float64) {
+	s := n.ctrnnState()
+
+	// Set the inputs
+	inputOffset := n.biasCount
+	for i := range n.nodes {
+		if t := n.nodes[i].NodeType(); t == BIAS || t == INPUT {
+			n.nodes[i].Reset()
+		}
+	}
+	for i := range inputs {
+		n.nodes[i+inputOffset].Combine(n.scaleInput(i, inputs[i]))
+	}
+
+	// Integrate the node states
+	for step := 0; step < steps; step++ {
+		switch n.integrator {
+		case RK4:
+			n.derivative(s, s.y, s.k1)
+			addScaled(s.tmp, s.y, s.k1, dt/2)
+			n.derivative(s, s.tmp, s.k2)
+			addScaled(s.tmp, s.y, s.k2, dt/2)
+			n.derivative(s, s.tmp, s.k3)
+			addScaled(s.tmp, s.y, s.k3, dt)
+			n.derivative(s, s.tmp, s.k4)
+			for i := range s.y {
+				s.y[i] += dt / 6 * (s.k1[i] + 2*s.k2[i] + 2*s.k3[i] + s.k4[i])
+			}
+		default:
+			n.derivative(s, s.y, s.k1)
+			addScaled(s.y, s.y, s.k1, dt)
+		}
+	}
+
+	// Return the outputs
+	outputOffset := inputOffset + n.inputCount
+	outputs = make([]float64, n.outputCount)
+	for i := range outputs {
+		outputs[i] = n.stateOutput(outputOffset+i, s.y)
+	}
+	transformOutputs(n.transform, outputs)
+	n.unscaleOutputs(outputs)
+	return
+}
+
+// Returns the CTRNN state, rebuilding it if the nodes or connections of the
+// Network have changed or been reordered. Node states carry over to the nodes
+// which remain.
+func (n *Network) ctrnnState() *ctrnn {
+	old := n.ctrnn
+	if old != nil && sameNodes(old.nodes, n.nodes) && sameConns(old.conns, n.conns) {
+		return old
+	}
+
+	size := len(n.nodes)
+	s := &ctrnn{
+		nodes:    append([]Node(nil), n.nodes...),
+		conns:    append([]Connection(nil), n.conns...),
+		index:    make(map[Node]int, size),
+		incoming: make([][]int, size),
+		y:        make([]float64, size),
+		k1:       make([]float64, size),
+		k2:       make([]float64, size),
+		k3:       make([]float64, size),
+		k4:       make([]float64, size),
+		tmp:      make([]float64, size),
+		outs:     make([]float64, size),
+	}
+	for i, x := range n.nodes {
+		s.index[x] = i
+		if old != nil {
+			if j, ok := old.index[x]; ok {
+				s.y[i] = old.y[j]
+			}
+		}
+	}
+	for c, conn := range n.conns {
+		if n.modulatory == 0 || !isModulatory(conn) {
+			j := s.index[conn.To()]
+			s.incoming[j] = append(s.incoming[j], c)
+		}
+	}
+	n.ctrnn = s
+	return s
+}
+
+// Returns true if a and b hold the same Nodes in the same order
+func sameNodes(a, b []Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if a and b hold the same Connections in the same order
+func sameConns(a, b []Connection) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the activation of node i when the node states are y
+func (n *Network) stateOutput(i int, y []float64) float64 {
+	x := n.nodes[i]
+	if t := x.NodeType(); t == HIDDEN || t == OUTPUT || t == MODULATORY {
+		x.Reset()
+		x.Combine(y[i])
+	}
+	return x.Activate()
+}
+
+// Sets dydt to the rate of change of the node states y
+func (n *Network) derivative(s *ctrnn, y, dydt []float64) {
+	for i := range n.nodes {
+		s.outs[i] = n.stateOutput(i, y)
+	}
+	for i, x := range n.nodes {
+		if t := x.NodeType(); t != HIDDEN && t != OUTPUT && t != MODULATORY {
+			dydt[i] = 0
+			continue
+		}
+		s.values = s.values[:0]
+		for _, c := range s.incoming[i] {
+			conn := n.conns[c]
+			w := conn.Weight()
+			if g := gaterOf(conn); g != nil {
+				w *= s.outs[s.index[g]]
+			}
+			s.values = append(s.values, s.outs[s.index[conn.From()]]*w)
+		}
+		dydt[i] = (-y[i] + aggregate(x.AggType(), s.values)) / x.TimeConstant()
+	}
+}
+
+// Sets dst to y + h * dydt
+func addScaled(dst, y, dydt []float64, h float64) {
+	for i := range dst {
+		dst[i] = y[i] + h*dydt[i]
+	}
+}
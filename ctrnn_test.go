@@ -0,0 +1,138 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+func TestCTRNN(t *testing.T) {
+	Convey("Subject: CTRNN", t, func() {
+
+		// A single leaky integrator: dy/dt = (-y + u) / tau, so y(t) = u * (1 - e^(-t/tau))
+		newLeaky := func(tau float64) *Network {
+			net, _ := NewGraph().Input("in").Output("out", DIRECT).Connect("in", "out", 1).Build()
+			net.nodes[1].SetTimeConstant(tau)
+			return net
+		}
+		exact := func(u, t, tau float64) float64 { return u * (1 - math.Exp(-t/tau)) }
+
+		Convey("Euler integration should approximate the exact solution", func() {
+			net := newLeaky(1)
+			out := net.Advance([]float64{2}, 0.01, 100)
+			So(out[0], ShouldAlmostEqual, exact(2, 1, 1), 0.01)
+		})
+		Convey("RK4 integration should be close to the exact solution", func() {
+			net := newLeaky(0.5)
+			net.SetIntegrator(RK4)
+			out := net.Advance([]float64{2}, 0.1, 10)
+			So(out[0], ShouldAlmostEqual, exact(2, 1, 0.5), 1e-4)
+		})
+		Convey("State should be kept between calls", func() {
+			net := newLeaky(1)
+			net.SetIntegrator(RK4)
+			net.Advance([]float64{2}, 0.1, 5)
+			out := net.Advance([]float64{2}, 0.1, 5)
+			So(out[0], ShouldAlmostEqual, exact(2, 1, 1), 1e-4)
+		})
+		Convey("ResetState should start again from 0", func() {
+			net := newLeaky(1)
+			net.Advance([]float64{2}, 0.1, 5)
+			net.ResetState()
+			So(net.Advance([]float64{2}, 0.1, 0)[0], ShouldEqual, 0.0)
+		})
+		Convey("Reordering the connections between calls should keep the states right", func() {
+			// in -> h -> out, with the connections added out of order in one copy
+			build := func(inOrder bool) *Network {
+				net := &Network{}
+				in, h, out := NewNode(DIRECT, INPUT), NewNode(TANH, HIDDEN), NewNode(SIGMOID, OUTPUT)
+				for _, x := range []Node{in, h, out} {
+					net.AddNode(x)
+				}
+				first, second := NewConnection(in, h, 1.5), NewConnection(h, out, -2)
+				if inOrder {
+					net.AddConnection(first)
+					net.AddConnection(second)
+				} else {
+					net.AddConnection(second)
+					net.AddConnection(first)
+				}
+				return net
+			}
+			sorted, unsorted := build(true), build(false)
+			unsorted.Advance([]float64{0.5}, 0.1, 5)
+			So(unsorted.SortConnections(), ShouldBeNil)
+			got := unsorted.Advance([]float64{0.5}, 0.1, 50)
+			So(got[0], ShouldAlmostEqual, sorted.Advance([]float64{0.5}, 0.1, 55)[0])
+		})
+		Convey("A time constant which is not positive should be rejected", func() {
+			net := newLeaky(2)
+			So(net.nodes[1].SetTimeConstant(0), ShouldEqual, ErrTimeConstant)
+			So(net.nodes[1].SetTimeConstant(-1), ShouldEqual, ErrTimeConstant)
+			So(net.nodes[1].SetTimeConstant(math.NaN()), ShouldEqual, ErrTimeConstant)
+			So(net.nodes[1].TimeConstant(), ShouldEqual, 2.0)
+
+			var restored Network
+			data := []byte(`{"nodes":[{"type":1,"func":0},{"type":2,"func":0,"tau":0}],"conns":[]}`)
+			So(json.Unmarshal(data, &restored), ShouldNotBeNil)
+		})
+		Convey("Activate should be unaffected by the CTRNN state", func() {
+			net := newLeaky(1)
+			net.Advance([]float64{2}, 0.1, 5)
+			So(net.Activate([]float64{3})[0], ShouldEqual, 3.0)
+		})
+		Convey("Recurrent connections should be allowed", func() {
+			net := &Network{}
+			in := NewNode(DIRECT, INPUT)
+			h := NewNode(TANH, HIDDEN)
+			out := NewNode(SIGMOID, OUTPUT)
+			net.AddNode(in)
+			net.AddNode(h)
+			net.AddNode(out)
+			net.AddConnection(NewConnection(in, h, 1))
+			net.AddConnection(NewConnection(h, out, 1))
+			net.AddConnection(NewConnection(out, h, -2))
+			net.AddConnection(NewConnection(h, h, 0.5))
+			net.SetIntegrator(RK4)
+			outputs := net.Advance([]float64{1}, 0.05, 200)
+			So(outputs[0], ShouldBeBetween, 0.0, 1.0)
+		})
+		Convey("Time constants and the integrator should be serialized", func() {
+			net := newLeaky(0.25)
+			net.SetIntegrator(RK4)
+			data, err := json.Marshal(net)
+			So(err, ShouldBeNil)
+			var copy Network
+			So(json.Unmarshal(data, &copy), ShouldBeNil)
+			So(copy.nodes[1].TimeConstant(), ShouldEqual, 0.25)
+			So(copy.integrator, ShouldEqual, RK4)
+		})
+	})
+}
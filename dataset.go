@@ -0,0 +1,122 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// Dataset holds samples for training and evaluating a Network. Inputs[i] and
+// Targets[i] are the inputs and expected outputs of sample i.
+type Dataset struct {
+	Inputs  [][]float64
+	Targets [][]float64
+}
+
+// Len returns the number of samples in the Dataset
+func (d Dataset) Len() int {
+	return len(d.Inputs)
+}
+
+// Add appends a sample to the Dataset
+func (d *Dataset) Add(inputs, targets []float64) {
+	d.Inputs = append(d.Inputs, inputs)
+	d.Targets = append(d.Targets, targets)
+}
+
+// Sample is one set of inputs and the outputs expected from them
+type Sample struct {
+	Inputs  []float64
+	Targets []float64
+}
+
+// Sample returns sample i of the Dataset
+func (d Dataset) Sample(i int) Sample {
+	return Sample{Inputs: d.Inputs[i], Targets: d.Targets[i]}
+}
+
+// Subset returns a Dataset holding the samples with the given indexes. The
+// samples themselves are shared, not copied.
+func (d Dataset) Subset(indexes []int) Dataset {
+	s := Dataset{
+		Inputs:  make([][]float64, len(indexes)),
+		Targets: make([][]float64, len(indexes)),
+	}
+	for i, j := range indexes {
+		s.Inputs[i] = d.Inputs[j]
+		s.Targets[i] = d.Targets[j]
+	}
+	return s
+}
+
+// Shuffle returns a copy of the Dataset with the samples in a random order. The
+// same seed always gives the same order.
+func (d Dataset) Shuffle(seed int64) Dataset {
+	return d.Subset(rand.New(rand.NewSource(seed)).Perm(d.Len()))
+}
+
+// Split divides the Dataset, in order, into training, validation and test sets.
+// The first fraction train of the samples go to the training set, the next
+// fraction validation to the validation set, and the rest to the test set.
+// Either fraction may be 0 to leave that set empty. Shuffle the Dataset first
+// for a random split.
+func (d Dataset) Split(train, validation float64) (trainSet, validationSet, testSet Dataset, err error) {
+	if !(train >= 0) || !(validation >= 0) || train+validation > 1 {
+		err = errors.New("neural: split fractions must not be negative and must sum to at most 1")
+		return
+	}
+
+	n := d.Len()
+	a := int(train*float64(n) + 0.5)
+	b := a + int(validation*float64(n)+0.5)
+	if b > n {
+		b = n
+	}
+
+	all := make([]int, n)
+	for i := range all {
+		all[i] = i
+	}
+	return d.Subset(all[:a]), d.Subset(all[a:b]), d.Subset(all[b:]), nil
+}
+
+// Checks that every sample in the Dataset fits the Network
+func (d Dataset) check(n *Network) error {
+	if len(d.Inputs) != len(d.Targets) {
+		return errors.New("neural: dataset has a different number of inputs and targets")
+	}
+	for i := range d.Inputs {
+		if len(d.Inputs[i]) != n.inputCount {
+			return ErrInputSize
+		}
+		if len(d.Targets[i]) != n.outputCount {
+			return ErrOutputSize
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestDataset(t *testing.T) {
+	Convey("Subject: Dataset", t, func() {
+		var ds Dataset
+		for i := 0; i < 10; i++ {
+			ds.Add([]float64{float64(i)}, []float64{float64(i * 2)})
+		}
+
+		Convey("Add should append samples", func() {
+			So(ds.Len(), ShouldEqual, 10)
+			So(ds.Targets[3][0], ShouldEqual, 6.0)
+		})
+		Convey("Shuffle should be deterministic and keep pairs together", func() {
+			a, b := ds.Shuffle(42), ds.Shuffle(42)
+			So(a, ShouldResemble, b)
+			So(a, ShouldNotResemble, ds)
+			for i := range a.Inputs {
+				So(a.Targets[i][0], ShouldEqual, a.Inputs[i][0]*2)
+			}
+			So(ds.Inputs[0][0], ShouldEqual, 0.0)
+		})
+		Convey("Split should divide the samples in order", func() {
+			train, validation, test, err := ds.Split(0.6, 0.2)
+			So(err, ShouldBeNil)
+			So(train.Len(), ShouldEqual, 6)
+			So(validation.Len(), ShouldEqual, 2)
+			So(test.Len(), ShouldEqual, 2)
+			So(validation.Inputs[0][0], ShouldEqual, 6.0)
+			So(test.Inputs[1][0], ShouldEqual, 9.0)
+		})
+		Convey("Split should reject fractions over 1", func() {
+			_, _, _, err := ds.Split(0.8, 0.3)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("Split should reject negative fractions but allow 0", func() {
+			_, _, _, err := ds.Split(-0.1, 0.5)
+			So(err, ShouldNotBeNil)
+			train, validation, test, err := ds.Split(0.7, 0)
+			So(err, ShouldBeNil)
+			So(train.Len(), ShouldEqual, 7)
+			So(validation.Len(), ShouldEqual, 0)
+			So(test.Len(), ShouldEqual, 3)
+		})
+	})
+}
@@ -0,0 +1,91 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"errors"
+	"github.com/boggo/random"
+)
+
+// Implemented by Nodes which can be dropped while training
+type dropoutNode interface {
+	keep() float64
+	setDropout(rate float64, dropped bool)
+}
+
+// SetDropout makes each training pass through Backprop or BackpropLoss drop
+// every HIDDEN node with probability rate, setting its activation to 0, and
+// scale up the activations of the rest by 1/(1-rate). Activate and the other
+// ways of running the Network are unaffected. rand returns uniform numbers in
+// [0,1); nil uses github.com/boggo/random. A rate of 0 turns dropout off.
+func (n *Network) SetDropout(rate float64, rand func() float64) error {
+	if rate < 0 || rate >= 1 {
+		return errors.New("neural: dropout rate must be at least 0 and less than 1")
+	}
+	if rand == nil {
+		rand = random.Next
+	}
+	n.dropout, n.dropoutRand = rate, rand
+	return nil
+}
+
+// Dropout returns the probability of dropping each hidden node while training
+func (n *Network) Dropout() float64 {
+	return n.dropout
+}
+
+// Chooses the hidden nodes to drop from a training pass
+func (n *Network) dropHidden() {
+	if n.dropout == 0 {
+		return
+	}
+	for _, x := range n.nodes {
+		if d, ok := x.(dropoutNode); ok && x.NodeType() == HIDDEN {
+			d.setDropout(n.dropout, n.dropoutRand() < n.dropout)
+		}
+	}
+}
+
+// Keeps every hidden node again after a training pass
+func (n *Network) keepHidden() {
+	if n.dropout == 0 {
+		return
+	}
+	for _, x := range n.nodes {
+		if d, ok := x.(dropoutNode); ok {
+			d.setDropout(0, false)
+		}
+	}
+}
+
+// Returns the factor dropout applies to the Node's activation
+func keepOf(x Node) float64 {
+	if d, ok := x.(dropoutNode); ok {
+		return d.keep()
+	}
+	return 1
+}
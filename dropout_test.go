@@ -0,0 +1,134 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+// Returns a source of random numbers which repeats values from the start of
+// each training pass, along with a function to rewind it
+func repeating(values ...float64) (func() float64, func()) {
+	i := 0
+	next := func() float64 {
+		x := values[i%len(values)]
+		i++
+		return x
+	}
+	return next, func() { i = 0 }
+}
+
+func TestDropout(t *testing.T) {
+	Convey("Subject: Dropout", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+		newNet := func() *Network {
+			random.Reseed(0)
+			net, _ := NewLayered(2, []LayerSpec{{Size: 4, Func: TANH}, {Size: 1, Func: SIGMOID}})
+			return net
+		}
+		inputs := []float64{0.4, -0.7}
+		targets := []float64{0.8}
+
+		Convey("The rate should be checked", func() {
+			net := newNet()
+			So(net.SetDropout(-0.1, nil), ShouldNotBeNil)
+			So(net.SetDropout(1, nil), ShouldNotBeNil)
+			So(net.SetDropout(0.5, nil), ShouldBeNil)
+			So(net.Dropout(), ShouldEqual, 0.5)
+		})
+		Convey("Activate should ignore dropout", func() {
+			net := newNet()
+			before := net.Activate(inputs)
+			net.SetDropout(0.5, nil)
+			net.BackpropLoss(SQUARED_ERROR, inputs, targets, net.NewGradients())
+			So(net.Activate(inputs), ShouldResemble, before)
+			So(net.Activate(inputs), ShouldResemble, before)
+		})
+		Convey("Dropping every hidden node should leave only the output bias", func() {
+			net := newNet()
+			net.SetDropout(0.5, func() float64 { return 0 })
+			grads := net.NewGradients()
+			net.BackpropLoss(SQUARED_ERROR, inputs, targets, grads)
+			for i, c := range net.conns {
+				if c.To().NodeType() == HIDDEN || c.From().NodeType() == HIDDEN {
+					So(grads.Weights[i], ShouldEqual, 0)
+				} else {
+					So(grads.Weights[i], ShouldNotEqual, 0)
+				}
+			}
+		})
+		Convey("The gradient should match the loss with the same nodes dropped", func() {
+			net := newNet()
+			rand, rewind := repeating(0.1, 0.9, 0.2, 0.7)
+			net.SetDropout(0.5, rand)
+			lossOf := func() float64 {
+				rewind()
+				return net.BackpropLoss(SQUARED_ERROR, inputs, targets, net.NewGradients())
+			}
+
+			rewind()
+			grads := net.NewGradients()
+			net.BackpropLoss(SQUARED_ERROR, inputs, targets, grads)
+			for i, c := range net.conns {
+				w := c.Weight()
+				c.SetWeight(w + 1e-6)
+				up := lossOf()
+				c.SetWeight(w - 1e-6)
+				down := lossOf()
+				c.SetWeight(w)
+				So(grads.Weights[i], ShouldAlmostEqual, (up-down)/2e-6, 1e-6)
+			}
+		})
+		Convey("Kept nodes should be scaled up", func() {
+			plain, dropped := newNet(), newNet()
+			dropped.SetDropout(0.5, func() float64 { return 0.9 })
+			ds := xorDataset()
+			a := plain.BackpropLoss(SQUARED_ERROR, ds.Inputs[1], ds.Targets[1], plain.NewGradients())
+			b := dropped.BackpropLoss(SQUARED_ERROR, ds.Inputs[1], ds.Targets[1], dropped.NewGradients())
+			So(b, ShouldNotEqual, a)
+
+			// Doubling the weights out of the hidden nodes gives the same loss
+			for _, c := range plain.conns {
+				if c.From().NodeType() == HIDDEN {
+					c.SetWeight(2 * c.Weight())
+				}
+			}
+			So(plain.BackpropLoss(SQUARED_ERROR, ds.Inputs[1], ds.Targets[1], plain.NewGradients()), ShouldAlmostEqual, b)
+		})
+		Convey("A Trainer should train with dropout", func() {
+			net := newNet()
+			net.SetDropout(0.1, nil)
+			before, _ := net.Evaluate(xorDataset(), SQUARED_ERROR)
+			trainer := &Trainer{LearningRate: 1, Epochs: 500, BatchSize: 2, Seed: 1}
+			So(trainer.Train(net, xorDataset()), ShouldBeNil)
+			after, _ := net.Evaluate(xorDataset(), SQUARED_ERROR)
+			So(after, ShouldBeLessThan, before)
+		})
+	})
+}
@@ -0,0 +1,100 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"math"
+)
+
+// GradCheckResult reports the connection whose gradient from BackpropLoss is
+// furthest from its numerical estimate
+type GradCheckResult struct {
+	MaxError   float64    // Relative error |a-n| / (|a|+|n|), or 0 if both are 0
+	Index      int        // Index of the connection in the Network
+	Connection Connection // The connection itself
+	Analytic   float64    // Gradient from BackpropLoss
+	Numeric    float64    // Gradient by central differences
+}
+
+// Step used by GradCheck for central differences
+const gradCheckStep = 1e-6
+
+// GradCheck compares the gradient of the loss with respect to each connection
+// weight, as found by BackpropLoss, with central finite differences on the
+// sample and returns the worst match. Dropout is turned off and the connections
+// are put in activation order during the check, then restored, so it returns
+// ErrCycle if they form a cycle. Activation functions with kinks, such as
+// RELU, can show large errors when a node's input lies within the step of the
+// kink.
+func GradCheck(n *Network, loss Loss, sample Sample) (GradCheckResult, error) {
+	if len(sample.Inputs) != n.inputCount {
+		return GradCheckResult{}, ErrInputSize
+	}
+	if len(sample.Targets) != n.outputCount {
+		return GradCheckResult{}, ErrOutputSize
+	}
+
+	conns := n.conns
+	sorted, err := sortConnections(conns)
+	if err != nil {
+		return GradCheckResult{}, err
+	}
+	position := make(map[Connection]int, len(conns))
+	for i, c := range conns {
+		position[c] = i
+	}
+
+	dropout := n.dropout
+	n.conns, n.dropout = sorted, 0
+	defer func() { n.conns, n.dropout = conns, dropout }()
+
+	grads := n.NewGradients()
+	n.BackpropLoss(loss, sample.Inputs, sample.Targets, grads)
+
+	scratch := n.NewGradients()
+	lossAt := func(c Connection, w float64) float64 {
+		c.SetWeight(w)
+		return n.BackpropLoss(loss, sample.Inputs, sample.Targets, scratch)
+	}
+
+	result := GradCheckResult{Index: -1}
+	for i, c := range n.conns {
+		w := c.Weight()
+		numeric := (lossAt(c, w+gradCheckStep) - lossAt(c, w-gradCheckStep)) / (2 * gradCheckStep)
+		c.SetWeight(w)
+
+		analytic := grads.Weights[i]
+		relative := 0.0
+		if sum := math.Abs(analytic) + math.Abs(numeric); sum > 0 {
+			relative = math.Abs(analytic-numeric) / sum
+		}
+		if result.Index < 0 || relative > result.MaxError {
+			result = GradCheckResult{relative, position[c], c, analytic, numeric}
+		}
+	}
+	return result, nil
+}
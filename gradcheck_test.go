@@ -0,0 +1,104 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"fmt"
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestGradCheck(t *testing.T) {
+	Convey("Subject: GradCheck", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+		sample := Sample{Inputs: []float64{0.3, -0.8, 0.5}, Targets: []float64{0.9, 0.1}}
+
+		for _, f := range FuncTypes {
+			f := f
+			Convey(fmt.Sprintf("Backprop should match finite differences for FuncType %d", f), func() {
+				random.Reseed(0)
+				net, _ := NewLayered(3, []LayerSpec{{Size: 4, Func: f}, {Size: 3, Func: f, Agg: MAX}, {Size: 2, Func: f}})
+				for _, x := range net.nodes {
+					x.SetBias(random.Next() - 0.5)
+				}
+				result, err := GradCheck(net, SQUARED_ERROR, sample)
+				So(err, ShouldBeNil)
+				So(result.MaxError, ShouldBeLessThan, 1e-4)
+				So(result.Connection, ShouldEqual, net.conns[result.Index])
+			})
+		}
+
+		Convey("Connections added out of order should still check", func() {
+			net := &Network{}
+			b, x := NewNode(DIRECT, BIAS), NewNode(DIRECT, INPUT)
+			h, g, y := NewNode(TANH, HIDDEN), NewNode(SIGMOID, HIDDEN), NewNode(SIGMOID, OUTPUT)
+			for _, node := range []Node{b, x, h, g, y} {
+				net.AddNode(node)
+			}
+			conns := []Connection{NewConnection(x, y, -0.4), NewConnection(g, y, 0.7), NewConnection(b, g, 0.3),
+				NewConnection(h, g, -1.2), NewConnection(x, h, 0.9)}
+			for _, c := range conns {
+				net.AddConnection(c)
+			}
+			result, err := GradCheck(net, CROSS_ENTROPY, Sample{Inputs: []float64{0.6}, Targets: []float64{1}})
+			So(err, ShouldBeNil)
+			So(result.MaxError, ShouldBeLessThan, 1e-4)
+			So(net.conns[result.Index], ShouldEqual, result.Connection)
+			for i, c := range conns {
+				So(net.conns[i], ShouldEqual, c)
+			}
+		})
+		Convey("Connections in a cycle should be rejected", func() {
+			net := &Network{}
+			x, h, g, y := NewNode(DIRECT, INPUT), NewNode(TANH, HIDDEN), NewNode(TANH, HIDDEN), NewNode(SIGMOID, OUTPUT)
+			for _, node := range []Node{x, h, g, y} {
+				net.AddNode(node)
+			}
+			for _, c := range []Connection{NewConnection(x, h, 1), NewConnection(h, g, 1), NewConnection(g, h, 1), NewConnection(g, y, 1)} {
+				net.AddConnection(c)
+			}
+			_, err := GradCheck(net, SQUARED_ERROR, Sample{Inputs: []float64{1}, Targets: []float64{0}})
+			So(err, ShouldEqual, ErrCycle)
+		})
+		Convey("Dropout should be turned off during the check", func() {
+			net, _ := NewLayered(3, []LayerSpec{{Size: 2, Func: TANH}, {Size: 2, Func: SIGMOID}})
+			net.SetDropout(0.5, func() float64 { return 0 })
+			result, err := GradCheck(net, SQUARED_ERROR, sample)
+			So(err, ShouldBeNil)
+			So(result.MaxError, ShouldBeLessThan, 1e-4)
+			So(net.Dropout(), ShouldEqual, 0.5)
+		})
+		Convey("A sample of the wrong shape should be rejected", func() {
+			net, _ := NewLayered(3, []LayerSpec{{Size: 2, Func: SIGMOID}})
+			_, err := GradCheck(net, SQUARED_ERROR, Sample{Inputs: []float64{1}, Targets: []float64{0, 0}})
+			So(err, ShouldEqual, ErrInputSize)
+			_, err = GradCheck(net, SQUARED_ERROR, Sample{Inputs: sample.Inputs, Targets: []float64{0}})
+			So(err, ShouldEqual, ErrOutputSize)
+		})
+	})
+}
@@ -0,0 +1,226 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Graph declares a Network by name. Nodes are declared with Bias, Input,
+// Hidden, Modulatory and Output and joined with Connect, in any order. Build
+// then creates the Network with its connections sorted so that each node
+// receives all of its inputs before its value is passed on, allowing skip
+// connections between any layers.
+type Graph struct {
+	names []string
+	nodes map[string]Node
+	edges []edge
+	err   error
+}
+
+// A declared connection between two named nodes
+type edge struct {
+	from, to string
+	weight   float64
+	gater    string // Empty unless the connection is gated
+}
+
+// Creates a new, empty Graph
+func NewGraph() *Graph {
+	return &Graph{nodes: make(map[string]Node)}
+}
+
+// Bias declares a bias node with the given name
+func (g *Graph) Bias(name string) *Graph {
+	return g.add(name, NewNode(DIRECT, BIAS))
+}
+
+// Input declares an input node with the given name. Inputs are fed in the
+// order in which they are declared.
+func (g *Graph) Input(name string) *Graph {
+	return g.add(name, NewNode(DIRECT, INPUT))
+}
+
+// Hidden declares a hidden node with the given name and activation function
+func (g *Graph) Hidden(name string, funcType FuncType) *Graph {
+	return g.add(name, NewNode(funcType, HIDDEN))
+}
+
+// Modulatory declares a modulatory node with the given name and activation
+// function. Its connections scale the plasticity of the connections into their
+// targets rather than adding to their input.
+func (g *Graph) Modulatory(name string, funcType FuncType) *Graph {
+	return g.add(name, NewNode(funcType, MODULATORY))
+}
+
+// Output declares an output node with the given name and activation function.
+// Outputs are returned in the order in which they are declared.
+func (g *Graph) Output(name string, funcType FuncType) *Graph {
+	return g.add(name, NewNode(funcType, OUTPUT))
+}
+
+// Connect declares a connection between two named nodes. The nodes do not
+// need to be declared yet.
+func (g *Graph) Connect(from, to string, weight float64) *Graph {
+	g.edges = append(g.edges, edge{from: from, to: to, weight: weight})
+	return g
+}
+
+// Gate declares a connection between two named nodes whose weight is
+// multiplied by the activation of a third, the gater. The nodes do not need to
+// be declared yet.
+func (g *Graph) Gate(from, to, gater string, weight float64) *Graph {
+	g.edges = append(g.edges, edge{from: from, to: to, weight: weight, gater: gater})
+	return g
+}
+
+// Node returns the node declared with the given name, or nil
+func (g *Graph) Node(name string) Node {
+	return g.nodes[name]
+}
+
+// Records a named node, keeping the first error encountered
+func (g *Graph) add(name string, node Node) *Graph {
+	switch {
+	case g.err != nil:
+	case node == nil:
+		g.err = fmt.Errorf("neural: node %q has an unknown function type", name)
+	case g.nodes[name] != nil:
+		g.err = fmt.Errorf("neural: node %q is declared twice", name)
+	default:
+		g.names = append(g.names, name)
+		g.nodes[name] = node
+	}
+	return g
+}
+
+// Build creates the Network described by the Graph. It fails if a connection
+// refers to an undeclared node, is declared twice, targets a bias or input
+// node, or if the connections form a cycle.
+func (g *Graph) Build() (*Network, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	network := &Network{}
+	for _, name := range g.names {
+		network.AddNode(g.nodes[name])
+	}
+
+	seen := make(map[edge]bool)
+	for _, e := range g.edges {
+		from, to := g.nodes[e.from], g.nodes[e.to]
+		switch {
+		case from == nil:
+			return nil, fmt.Errorf("neural: connection from undeclared node %q", e.from)
+		case to == nil:
+			return nil, fmt.Errorf("neural: connection to undeclared node %q", e.to)
+		case to.NodeType() == BIAS || to.NodeType() == INPUT:
+			return nil, fmt.Errorf("neural: connection into %q which is not a hidden or output node", e.to)
+		case seen[edge{from: e.from, to: e.to}]:
+			return nil, fmt.Errorf("neural: connection from %q to %q is declared twice", e.from, e.to)
+		case e.gater != "" && g.nodes[e.gater] == nil:
+			return nil, fmt.Errorf("neural: connection gated by undeclared node %q", e.gater)
+		}
+		seen[edge{from: e.from, to: e.to}] = true
+		if e.gater != "" {
+			network.AddConnection(NewGatedConnection(from, to, g.nodes[e.gater], e.weight))
+		} else {
+			network.AddConnection(NewConnection(from, to, e.weight))
+		}
+	}
+
+	if err := network.SortConnections(); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// ErrCycle is returned when the connections of a Network form a cycle and so
+// have no activation order
+var ErrCycle = errors.New("neural: connections form a cycle")
+
+// Orders conns so that every connection into a node comes before any connection
+// out of it or gated by it. Connections otherwise keep their relative order.
+func sortConnections(conns connList) (connList, error) {
+
+	// Count the connections into each node and the nodes each connection waits
+	// for, and note the order in which nodes appear
+	pending := make(map[Node]int)
+	waiting := make(map[Connection]int)
+	out := make(map[Node][]Connection)
+	var order []Node
+	for _, c := range conns {
+		sources := []Node{c.From()}
+		if g := gaterOf(c); g != nil && g != c.From() {
+			sources = append(sources, g)
+		}
+		for _, x := range append(sources, c.To()) {
+			if _, ok := pending[x]; !ok {
+				pending[x] = 0
+				order = append(order, x)
+			}
+		}
+		pending[c.To()]++
+		waiting[c] = len(sources)
+		for _, x := range sources {
+			out[x] = append(out[x], c)
+		}
+	}
+
+	// Start with the nodes which have no incoming connections
+	var ready []Node
+	for _, x := range order {
+		if pending[x] == 0 {
+			ready = append(ready, x)
+		}
+	}
+
+	// Release the connections of each node once all its inputs, and those of
+	// any gater, are in place
+	sorted := make(connList, 0, len(conns))
+	for len(ready) > 0 {
+		x := ready[0]
+		ready = ready[1:]
+		for _, c := range out[x] {
+			if waiting[c]--; waiting[c] > 0 {
+				continue
+			}
+			sorted = append(sorted, c)
+			pending[c.To()]--
+			if pending[c.To()] == 0 {
+				ready = append(ready, c.To())
+			}
+		}
+	}
+
+	if len(sorted) < len(conns) {
+		return nil, ErrCycle
+	}
+	return sorted, nil
+}
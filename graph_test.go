@@ -0,0 +1,107 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+func TestGraph(t *testing.T) {
+	Convey("Subject: Graph", t, func() {
+		sig := func(x float64) float64 { return 1.0 / (1.0 + math.Exp(-x)) }
+
+		Convey("Given a graph with skip connections declared out of order", func() {
+			g := NewGraph().
+				Output("out", SIGMOID).
+				Connect("h2", "out", 0.7).
+				Connect("in1", "out", -0.4).
+				Connect("h1", "h2", 0.6).
+				Connect("bias", "h1", 0.1).
+				Connect("in1", "h1", 0.5).
+				Connect("in2", "h2", -0.3).
+				Hidden("h2", SIGMOID).
+				Hidden("h1", SIGMOID).
+				Input("in1").
+				Input("in2").
+				Bias("bias")
+			net, err := g.Build()
+			So(err, ShouldBeNil)
+
+			Convey("It should have the right number of components", func() {
+				So(net.biasCount, ShouldEqual, 1)
+				So(net.inputCount, ShouldEqual, 2)
+				So(net.hiddenCount, ShouldEqual, 2)
+				So(net.outputCount, ShouldEqual, 1)
+				So(len(net.conns), ShouldEqual, 6)
+			})
+			Convey("Nodes should be available by name", func() {
+				So(g.Node("h1").FuncType(), ShouldEqual, SIGMOID)
+				So(g.Node("missing"), ShouldBeNil)
+			})
+			Convey("Activation should follow the computed order", func() {
+				in1, in2 := 0.25, 0.75
+				h1 := sig(0.1 + 0.5*in1)
+				h2 := sig(0.6*h1 - 0.3*in2)
+				out := sig(0.7*h2 - 0.4*in1)
+				outputs := net.Activate([]float64{in1, in2})
+				So(outputs[0], ShouldAlmostEqual, out)
+			})
+		})
+
+		Convey("Given an invalid graph", func() {
+			g := func() *Graph {
+				return NewGraph().Input("in").Hidden("h", SIGMOID).Output("out", SIGMOID)
+			}
+			Convey("A duplicate name should fail", func() {
+				_, err := g().Input("in").Build()
+				So(err, ShouldNotBeNil)
+			})
+			Convey("An unknown function type should fail", func() {
+				_, err := g().Hidden("h2", FuncType(99)).Build()
+				So(err, ShouldNotBeNil)
+			})
+			Convey("An undeclared node should fail", func() {
+				_, err := g().Connect("in", "nowhere", 1).Build()
+				So(err, ShouldNotBeNil)
+			})
+			Convey("A connection into an input should fail", func() {
+				_, err := g().Connect("h", "in", 1).Build()
+				So(err, ShouldNotBeNil)
+			})
+			Convey("A duplicate connection should fail", func() {
+				_, err := g().Connect("in", "h", 1).Connect("in", "h", 2).Build()
+				So(err, ShouldNotBeNil)
+			})
+			Convey("A cycle should fail", func() {
+				_, err := g().Connect("in", "h", 1).Connect("h", "out", 1).Connect("out", "h", 1).Build()
+				So(err, ShouldEqual, ErrCycle)
+			})
+		})
+	})
+}
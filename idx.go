@@ -0,0 +1,201 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// Data types of an IDX file
+const (
+	idxUnsignedByte = 0x08
+	idxSignedByte   = 0x09
+	idxShort        = 0x0B
+	idxInt          = 0x0C
+	idxFloat        = 0x0D
+	idxDouble       = 0x0E
+)
+
+// LoadIDXFiles reads a Dataset from a pair of IDX files on disk, such as the
+// MNIST or Fashion-MNIST images and labels. Either file may be gzip compressed.
+// See LoadIDX.
+func LoadIDXFiles(imagePath, labelPath string, outputCount int) (Dataset, error) {
+	images, err := os.Open(imagePath)
+	if err != nil {
+		return Dataset{}, err
+	}
+	defer images.Close()
+
+	labels, err := os.Open(labelPath)
+	if err != nil {
+		return Dataset{}, err
+	}
+	defer labels.Close()
+
+	return LoadIDX(images, labels, outputCount)
+}
+
+// LoadIDX reads a Dataset from IDX data. The images must be unsigned bytes, and
+// each becomes one sample whose inputs are its pixels scaled to [0,1]. Each
+// label becomes a one-hot target of outputCount values. Gzip compressed data
+// is detected and decompressed.
+func LoadIDX(images, labels io.Reader, outputCount int) (Dataset, error) {
+	imageType, imageDims, pixels, err := readIDX(images)
+	if err != nil {
+		return Dataset{}, fmt.Errorf("neural: reading IDX images: %v", err)
+	}
+	if imageType != idxUnsignedByte {
+		return Dataset{}, fmt.Errorf("neural: IDX images have data type 0x%02X, not unsigned bytes", imageType)
+	}
+
+	_, labelDims, values, err := readIDX(labels)
+	if err != nil {
+		return Dataset{}, fmt.Errorf("neural: reading IDX labels: %v", err)
+	}
+	if len(labelDims) != 1 {
+		return Dataset{}, fmt.Errorf("neural: IDX labels have %d dimensions, not 1", len(labelDims))
+	}
+	if imageDims[0] != labelDims[0] {
+		return Dataset{}, fmt.Errorf("neural: %d IDX images but %d labels", imageDims[0], labelDims[0])
+	}
+
+	count := imageDims[0]
+	size := 0
+	if count > 0 {
+		size = len(pixels) / count
+	}
+
+	ds := Dataset{
+		Inputs:  make([][]float64, count),
+		Targets: make([][]float64, count),
+	}
+	for i := 0; i < count; i++ {
+		ds.Inputs[i] = pixels[i*size : (i+1)*size : (i+1)*size]
+		for j := range ds.Inputs[i] {
+			ds.Inputs[i][j] /= 255
+		}
+
+		label := values[i]
+		if label != math.Trunc(label) || label < 0 || int(label) >= outputCount {
+			return Dataset{}, fmt.Errorf("neural: IDX label %d is %v, outside 0 to %d", i, label, outputCount-1)
+		}
+		ds.Targets[i] = make([]float64, outputCount)
+		ds.Targets[i][int(label)] = 1
+	}
+	return ds, nil
+}
+
+// Reads an IDX file, decompressing it first if needed, and returns its data
+// type, dimensions and values
+func readIDX(r io.Reader) (dataType byte, dims []int, values []float64, err error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		defer gz.Close()
+		br = bufio.NewReader(gz)
+	}
+
+	// Magic number: two zero bytes, the data type and the number of dimensions
+	var header [4]byte
+	if _, err = io.ReadFull(br, header[:]); err != nil {
+		return
+	}
+	if header[0] != 0 || header[1] != 0 {
+		return 0, nil, nil, fmt.Errorf("bad magic number % X", header)
+	}
+	dataType = header[2]
+
+	var width int
+	switch dataType {
+	case idxUnsignedByte, idxSignedByte:
+		width = 1
+	case idxShort:
+		width = 2
+	case idxInt, idxFloat:
+		width = 4
+	case idxDouble:
+		width = 8
+	default:
+		return 0, nil, nil, fmt.Errorf("unknown data type 0x%02X", dataType)
+	}
+	if header[3] == 0 {
+		return 0, nil, nil, fmt.Errorf("no dimensions")
+	}
+
+	// Dimensions, each a big endian 32 bit integer
+	dims = make([]int, header[3])
+	total := 1
+	for i := range dims {
+		var d uint32
+		if err = binary.Read(br, binary.BigEndian, &d); err != nil {
+			return
+		}
+		dims[i] = int(d)
+		if dims[i] != 0 && total > math.MaxInt32/width/dims[i] {
+			return 0, nil, nil, fmt.Errorf("dimensions %v are too large", dims[:i+1])
+		}
+		total *= dims[i]
+	}
+
+	// Values, stored big endian. The header is not trusted with the size of the
+	// buffer, which only grows as the data arrives.
+	raw, err := io.ReadAll(io.LimitReader(br, int64(total*width)))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if len(raw) < total*width {
+		return 0, nil, nil, fmt.Errorf("expected %d values but found %d", total, len(raw)/width)
+	}
+	values = make([]float64, total)
+	for i := range values {
+		b := raw[i*width : (i+1)*width]
+		switch dataType {
+		case idxUnsignedByte:
+			values[i] = float64(b[0])
+		case idxSignedByte:
+			values[i] = float64(int8(b[0]))
+		case idxShort:
+			values[i] = float64(int16(binary.BigEndian.Uint16(b)))
+		case idxInt:
+			values[i] = float64(int32(binary.BigEndian.Uint32(b)))
+		case idxFloat:
+			values[i] = float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+		case idxDouble:
+			values[i] = math.Float64frombits(binary.BigEndian.Uint64(b))
+		}
+	}
+	return dataType, dims, values, nil
+}
@@ -0,0 +1,128 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Returns an IDX file with the given data type, dimensions and raw data
+func idxBytes(dataType byte, dims []uint32, data []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0, 0, dataType, byte(len(dims))})
+	for _, d := range dims {
+		binary.Write(&buf, binary.BigEndian, d)
+	}
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+// Returns data compressed with gzip
+func gzipBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestIDX(t *testing.T) {
+	Convey("Subject: IDX", t, func() {
+		// Three 2x2 images and their labels
+		images := idxBytes(idxUnsignedByte, []uint32{3, 2, 2}, []byte{0, 255, 51, 102, 255, 255, 0, 0, 1, 2, 3, 4})
+		labels := idxBytes(idxUnsignedByte, []uint32{3}, []byte{2, 0, 9})
+
+		Convey("Images and labels should become a Dataset", func() {
+			ds, err := LoadIDX(bytes.NewReader(images), bytes.NewReader(labels), 10)
+			So(err, ShouldBeNil)
+			So(ds.Len(), ShouldEqual, 3)
+			So(ds.Inputs[0], ShouldResemble, []float64{0, 1, 0.2, 0.4})
+			So(len(ds.Targets[2]), ShouldEqual, 10)
+			So(ds.Targets[0][2], ShouldEqual, 1.0)
+			So(ds.Targets[2][9], ShouldEqual, 1.0)
+			sum := 0.0
+			for _, x := range ds.Targets[1] {
+				sum += x
+			}
+			So(sum, ShouldEqual, 1.0)
+		})
+		Convey("Gzip compressed files should be read", func() {
+			dir := t.TempDir()
+			imagePath := filepath.Join(dir, "images.idx3-ubyte.gz")
+			labelPath := filepath.Join(dir, "labels.idx1-ubyte")
+			os.WriteFile(imagePath, gzipBytes(images), 0644)
+			os.WriteFile(labelPath, labels, 0644)
+			ds, err := LoadIDXFiles(imagePath, labelPath, 10)
+			So(err, ShouldBeNil)
+			So(ds.Inputs[1], ShouldResemble, []float64{1, 1, 0, 0})
+		})
+		Convey("A label outside the outputs should fail", func() {
+			_, err := LoadIDX(bytes.NewReader(images), bytes.NewReader(labels), 5)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("Mismatched counts should fail", func() {
+			short := idxBytes(idxUnsignedByte, []uint32{2}, []byte{1, 2})
+			_, err := LoadIDX(bytes.NewReader(images), bytes.NewReader(short), 10)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("Truncated data should fail", func() {
+			_, err := LoadIDX(bytes.NewReader(images[:10]), bytes.NewReader(labels), 10)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("A header claiming more data than the file holds should fail", func() {
+			huge := idxBytes(idxUnsignedByte, []uint32{60000, 28, 28}, []byte{1, 2, 3, 4})
+			_, _, _, err := readIDX(bytes.NewReader(huge))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "found 4")
+		})
+		Convey("A header whose size overflows should fail", func() {
+			huge := idxBytes(idxDouble, []uint32{0xFFFFFFFF, 0xFFFFFFFF, 0xFFFFFFFF}, nil)
+			_, _, _, err := readIDX(bytes.NewReader(huge))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "too large")
+		})
+		Convey("A bad magic number should fail", func() {
+			bad := append([]byte{1}, images[1:]...)
+			_, err := LoadIDX(bytes.NewReader(bad), bytes.NewReader(labels), 10)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("Other data types should be read", func() {
+			var data bytes.Buffer
+			binary.Write(&data, binary.BigEndian, []int16{-2, 300})
+			_, dims, values, err := readIDX(bytes.NewReader(idxBytes(idxShort, []uint32{2}, data.Bytes())))
+			So(err, ShouldBeNil)
+			So(dims, ShouldResemble, []int{2})
+			So(values, ShouldResemble, []float64{-2, 300})
+		})
+	})
+}
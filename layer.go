@@ -0,0 +1,140 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"errors"
+	"fmt"
+	"github.com/boggo/random"
+)
+
+// LayerSpec describes one layer of nodes created by NewLayered
+type LayerSpec struct {
+	Size int      // Number of nodes in the layer
+	Func FuncType // Activation function used by the nodes in the layer
+	Agg  AggType  // How the nodes in the layer combine their inputs
+
+	// NoBias leaves the nodes in the layer unconnected from the bias node
+	NoBias bool
+
+	// Density is the fraction of possible connections from the previous layer
+	// which are created. Zero or one means the layers are fully connected.
+	Density float64
+}
+
+// Creates a new Network with numInput input nodes followed by the given layers.
+// The last layer holds the output nodes, all others are hidden layers. Each
+// layer is connected to the one before it, starting with the input nodes.
+func NewLayered(numInput int, layers []LayerSpec) (*Network, error) {
+
+	// Validate the specification
+	if numInput < 1 {
+		return nil, errors.New("neural: a layered network needs at least one input")
+	}
+	if len(layers) == 0 {
+		return nil, errors.New("neural: a layered network needs at least one layer")
+	}
+	for i, spec := range layers {
+		if spec.Size < 1 {
+			return nil, fmt.Errorf("neural: layer %d has size %d", i, spec.Size)
+		}
+		if spec.Density < 0 || spec.Density > 1 {
+			return nil, fmt.Errorf("neural: layer %d has density %v outside [0,1]", i, spec.Density)
+		}
+		if NewNode(spec.Func, HIDDEN) == nil {
+			return nil, fmt.Errorf("neural: layer %d has unknown function type %d", i, spec.Func)
+		}
+	}
+
+	network := &Network{}
+
+	// Add the bias node
+	bias := NewNode(DIRECT, BIAS)
+	network.AddNode(bias)
+
+	// Add the input nodes
+	prev := make([]Node, numInput)
+	for i := range prev {
+		prev[i] = NewNode(DIRECT, INPUT)
+		network.AddNode(prev[i])
+	}
+
+	// Add each layer and connect it to the previous one
+	for l, spec := range layers {
+		nodeType := HIDDEN
+		if l == len(layers)-1 {
+			nodeType = OUTPUT
+		}
+
+		layer := make([]Node, spec.Size)
+		for i := range layer {
+			layer[i] = NewNodeAgg(spec.Func, nodeType, spec.Agg)
+			network.AddNode(layer[i])
+		}
+
+		for _, tgt := range layer {
+
+			// Connect to the bias node
+			if !spec.NoBias {
+				network.AddConnection(NewConnection(bias, tgt, random.Next()*2-1))
+			}
+
+			// Connect to the previous layer
+			network.connectLayer(prev, tgt, spec.Density)
+		}
+		prev = layer
+	}
+
+	// Return the network
+	return network, nil
+}
+
+// Connects the nodes in src to tgt. A density between zero and one creates each
+// connection with that probability, ensuring at least one connection is made.
+func (n *Network) connectLayer(src []Node, tgt Node, density float64) {
+
+	// Fully connected
+	if density == 0 || density == 1 {
+		for _, s := range src {
+			n.AddConnection(NewConnection(s, tgt, random.Next()*2-1))
+		}
+		return
+	}
+
+	// Sparsely connected
+	added := 0
+	for _, s := range src {
+		if random.Next() < density {
+			n.AddConnection(NewConnection(s, tgt, random.Next()*2-1))
+			added++
+		}
+	}
+	if added == 0 {
+		s := src[int(random.Next()*float64(len(src)))%len(src)]
+		n.AddConnection(NewConnection(s, tgt, random.Next()*2-1))
+	}
+}
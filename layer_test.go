@@ -0,0 +1,124 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestLayered(t *testing.T) {
+	Convey("Subject: Layered Network", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+		specs := []LayerSpec{{Size: 4, Func: RELU}, {Size: 5, Func: TANH}, {Size: 2, Func: SIGMOID}}
+
+		Convey("Given a dense specification", func() {
+			net, err := NewLayered(3, specs)
+			So(err, ShouldBeNil)
+			Convey("It should have the right number of components", func() {
+				So(len(net.nodes), ShouldEqual, 15)
+				So(len(net.conns), ShouldEqual, 4*(1+3)+5*(1+4)+2*(1+5))
+				So(net.biasCount, ShouldEqual, 1)
+				So(net.inputCount, ShouldEqual, 3)
+				So(net.outputCount, ShouldEqual, 2)
+				So(net.hiddenCount, ShouldEqual, 9)
+			})
+			Convey("Each layer should use its activation function", func() {
+				for i := 0; i < 4; i++ {
+					So(net.nodes[6+i].FuncType(), ShouldEqual, RELU)
+				}
+				for i := 0; i < 5; i++ {
+					So(net.nodes[10+i].FuncType(), ShouldEqual, TANH)
+				}
+				So(net.nodes[4].FuncType(), ShouldEqual, SIGMOID)
+				So(net.nodes[5].FuncType(), ShouldEqual, SIGMOID)
+			})
+			Convey("Activation should return one value per output", func() {
+				outputs := net.Activate([]float64{0.1, 0.2, 0.3})
+				So(len(outputs), ShouldEqual, 2)
+			})
+		})
+
+		Convey("Given a specification without bias connections", func() {
+			specs[0].NoBias = true
+			net, err := NewLayered(3, specs)
+			So(err, ShouldBeNil)
+			So(len(net.conns), ShouldEqual, 4*3+5*(1+4)+2*(1+5))
+		})
+
+		Convey("Given a sparse specification", func() {
+			sparse := []LayerSpec{{Size: 20, Func: RELU, Density: 0.1}, {Size: 3, Func: SIGMOID, Density: 0.1}}
+			net, err := NewLayered(10, sparse)
+			So(err, ShouldBeNil)
+			Convey("There should be fewer connections than a dense network", func() {
+				So(len(net.conns), ShouldBeLessThan, 20*(1+10)+3*(1+20))
+			})
+			Convey("Every node should have at least one incoming connection besides the bias", func() {
+				incoming := make(map[Node]int)
+				for _, c := range net.conns {
+					if c.(*connection).fromNode.NodeType() != BIAS {
+						incoming[c.(*connection).toNode]++
+					}
+				}
+				for _, x := range net.nodes {
+					if x.NodeType() == HIDDEN || x.NodeType() == OUTPUT {
+						So(incoming[x], ShouldBeGreaterThan, 0)
+					}
+				}
+			})
+			Convey("The input nodes should keep their order", func() {
+				for i := 0; i < 10; i++ {
+					So(net.nodes[1+i].NodeType(), ShouldEqual, INPUT)
+				}
+			})
+		})
+
+		Convey("Given an invalid specification", func() {
+			Convey("No inputs should fail", func() {
+				_, err := NewLayered(0, specs)
+				So(err, ShouldNotBeNil)
+			})
+			Convey("No layers should fail", func() {
+				_, err := NewLayered(2, nil)
+				So(err, ShouldNotBeNil)
+			})
+			Convey("An empty layer should fail", func() {
+				_, err := NewLayered(2, []LayerSpec{{Size: 0, Func: SIGMOID}})
+				So(err, ShouldNotBeNil)
+			})
+			Convey("A density outside [0,1] should fail", func() {
+				_, err := NewLayered(2, []LayerSpec{{Size: 2, Func: SIGMOID, Density: 1.5}})
+				So(err, ShouldNotBeNil)
+			})
+			Convey("An unknown function should fail", func() {
+				_, err := NewLayered(2, []LayerSpec{{Size: 2, Func: FuncType(99)}})
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
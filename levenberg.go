@@ -0,0 +1,228 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"errors"
+	"math"
+)
+
+// LevenbergMarquardt trains a Network on the sum of squared errors using the
+// Jacobian of the outputs with respect to every connection weight. Each
+// iteration solves the damped normal equations (JᵀJ + λI)δ = -Jᵀr. λ shrinks
+// after a step that lowers the error and grows until one does. Memory and time
+// grow with the square and cube of the number of connections, so it suits
+// networks of up to a few hundred. Zero fields take the defaults shown; Factor
+// must be above 1 and Lambda and MaxLambda positive.
+type LevenbergMarquardt struct {
+	Iterations int     // Number of accepted steps to take, 100
+	Lambda     float64 // Starting damping, 0.01
+	Factor     float64 // Factor for raising and lowering the damping, 10
+	MaxLambda  float64 // Damping at which training stops, 1e10
+	MinError   float64 // Sum of squared errors at which training stops, 0
+}
+
+// Train adjusts the connection weights of the Network to reduce the squared
+// error on the Dataset. Output transforms are not supported, and dropout is
+// turned off while training. Train is a TrainFunc.
+func (lm *LevenbergMarquardt) Train(n *Network, ds Dataset) error {
+	if err := ds.check(n); err != nil {
+		return err
+	}
+	if ds.Len() == 0 {
+		return errors.New("neural: cannot train on an empty dataset")
+	}
+	if n.transform != NO_TRANSFORM {
+		return errors.New("neural: Levenberg-Marquardt does not support output transforms")
+	}
+
+	dropout := n.dropout
+	n.dropout = 0
+	defer func() { n.dropout = dropout }()
+
+	lambda := orDefault(lm.Lambda, 0.01)
+	factor := orDefault(lm.Factor, 10)
+	maxLambda := orDefault(lm.MaxLambda, 1e10)
+	iterations := lm.Iterations
+	if iterations <= 0 {
+		iterations = 100
+	}
+	if !(factor > 1) || !(lambda > 0) || !(maxLambda > 0) {
+		return errors.New("neural: Levenberg-Marquardt needs a Factor above 1 and a positive Lambda and MaxLambda")
+	}
+
+	m := len(n.conns)
+	weights := make([]float64, m)
+	a := make([][]float64, m)
+	for i := range a {
+		a[i] = make([]float64, m)
+	}
+	step := make([]float64, m)
+
+	for it := 0; it < iterations; it++ {
+		jtj, jtr, e := n.normalEquations(ds)
+		if math.IsNaN(e) || math.IsInf(e, 0) {
+			return errors.New("neural: Levenberg-Marquardt error is not finite")
+		}
+		if e <= lm.MinError {
+			return nil
+		}
+		for i, c := range n.conns {
+			weights[i] = c.Weight()
+		}
+
+		for {
+			for i := range a {
+				copy(a[i], jtj[i])
+				a[i][i] += lambda
+				step[i] = -jtr[i]
+			}
+			if cholesky(a) {
+				solveCholesky(a, step)
+				for i, c := range n.conns {
+					c.SetWeight(weights[i] + step[i])
+				}
+				if n.squaredError(ds) < e {
+					if l := lambda / factor; l > 0 {
+						lambda = l
+					}
+					break
+				}
+			}
+
+			// Undo the step and damp harder
+			for i, c := range n.conns {
+				c.SetWeight(weights[i])
+			}
+			if lambda *= factor; lambda > maxLambda {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// Returns JᵀJ and Jᵀr, where J is the Jacobian of every output of every sample
+// with respect to the connection weights and r the residuals, together with
+// half the sum of squared residuals
+func (n *Network) normalEquations(ds Dataset) ([][]float64, []float64, float64) {
+	m := len(n.conns)
+	jtj := make([][]float64, m)
+	for i := range jtj {
+		jtj[i] = make([]float64, m)
+	}
+	jtr := make([]float64, m)
+
+	e := 0.0
+	outputs := make([]float64, n.outputCount)
+	unit := make([]float64, n.outputCount)
+	row := n.NewGradients()
+	for s := range ds.Inputs {
+		n.forward(ds.Inputs[s], outputs)
+		targets := n.scaleTargets(ds.Targets[s])
+		for k := range outputs {
+			r := outputs[k] - targets[k]
+			e += r * r / 2
+
+			// The gradient of output k alone is its row of the Jacobian
+			row.Reset()
+			unit[k] = 1
+			n.backward(n.biasCount+n.inputCount, unit, row)
+			unit[k] = 0
+
+			j := row.Weights
+			for p := range j {
+				if j[p] == 0 {
+					continue
+				}
+				jtr[p] += j[p] * r
+				for q := 0; q <= p; q++ {
+					jtj[p][q] += j[p] * j[q]
+				}
+			}
+		}
+	}
+	for p := range jtj {
+		for q := 0; q < p; q++ {
+			jtj[q][p] = jtj[p][q]
+		}
+	}
+	return jtj, jtr, e
+}
+
+// Returns half the sum of squared errors of the Network on the Dataset, in
+// scaled units
+func (n *Network) squaredError(ds Dataset) float64 {
+	e := 0.0
+	outputs := make([]float64, n.outputCount)
+	for s := range ds.Inputs {
+		n.forward(ds.Inputs[s], outputs)
+		for k, t := range n.scaleTargets(ds.Targets[s]) {
+			e += (outputs[k] - t) * (outputs[k] - t) / 2
+		}
+	}
+	return e
+}
+
+// Replaces the lower triangle of the symmetric matrix a with its Cholesky
+// factor L, where a = LLᵀ. Returns false if a is not positive definite.
+func cholesky(a [][]float64) bool {
+	for j := range a {
+		d := a[j][j]
+		for k := 0; k < j; k++ {
+			d -= a[j][k] * a[j][k]
+		}
+		if d <= 0 || math.IsNaN(d) {
+			return false
+		}
+		a[j][j] = math.Sqrt(d)
+		for i := j + 1; i < len(a); i++ {
+			s := a[i][j]
+			for k := 0; k < j; k++ {
+				s -= a[i][k] * a[j][k]
+			}
+			a[i][j] = s / a[j][j]
+		}
+	}
+	return true
+}
+
+// Solves LLᵀx = b in place, given the Cholesky factor from cholesky
+func solveCholesky(l [][]float64, b []float64) {
+	for i := range b {
+		for k := 0; k < i; k++ {
+			b[i] -= l[i][k] * b[k]
+		}
+		b[i] /= l[i][i]
+	}
+	for i := len(b) - 1; i >= 0; i-- {
+		for k := i + 1; k < len(b); k++ {
+			b[i] -= l[k][i] * b[k]
+		}
+		b[i] /= l[i][i]
+	}
+}
@@ -0,0 +1,97 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+func TestLevenbergMarquardt(t *testing.T) {
+	Convey("Subject: Levenberg-Marquardt", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+
+		Convey("Cholesky should solve a positive definite system", func() {
+			a := [][]float64{{4, 2, 2}, {2, 5, 3}, {2, 3, 6}}
+			b := []float64{8, 10, 11} // The row sums, so x is all ones
+			So(cholesky(a), ShouldBeTrue)
+			solveCholesky(a, b)
+			So(b[0], ShouldAlmostEqual, 1)
+			So(b[1], ShouldAlmostEqual, 1)
+			So(b[2], ShouldAlmostEqual, 1)
+		})
+		Convey("Cholesky should reject an indefinite matrix", func() {
+			So(cholesky([][]float64{{1, 2}, {2, 1}}), ShouldBeFalse)
+		})
+		Convey("The Jacobian should match the loss gradient", func() {
+			net, _ := NewLayered(2, []LayerSpec{{Size: 3, Func: TANH}, {Size: 2, Func: SIGMOID}})
+			var ds Dataset
+			ds.Add([]float64{0.3, -0.5}, []float64{0.2, 0.9})
+			_, jtr, e := net.normalEquations(ds)
+			grads := net.NewGradients()
+			loss := net.BackpropLoss(SQUARED_ERROR, ds.Inputs[0], ds.Targets[0], grads)
+			So(e, ShouldAlmostEqual, loss)
+			for i := range jtr {
+				So(jtr[i], ShouldAlmostEqual, grads.Weights[i])
+			}
+		})
+		Convey("Training should fit a curve in a few iterations", func() {
+			net, _ := NewLayered(1, []LayerSpec{{Size: 6, Func: TANH}, {Size: 1, Func: DIRECT}})
+			var ds Dataset
+			for i := 0; i <= 20; i++ {
+				x := float64(i)/10 - 1
+				ds.Add([]float64{x}, []float64{x * x})
+			}
+			var train TrainFunc = (&LevenbergMarquardt{Iterations: 50}).Train
+			So(train(net, ds), ShouldBeNil)
+			r, _ := net.EvaluateRegression(ds)
+			So(r.RMSE, ShouldBeLessThan, 0.01)
+		})
+		Convey("Bad settings should be rejected rather than loop forever", func() {
+			net, _ := NewLayered(1, []LayerSpec{{Size: 1, Func: DIRECT}})
+			var ds Dataset
+			ds.Add([]float64{0}, []float64{1})
+			ds.Add([]float64{1}, []float64{0})
+			for _, lm := range []*LevenbergMarquardt{{Factor: 0.5}, {Factor: 1}, {Lambda: -1}, {MaxLambda: -1}} {
+				So(lm.Train(net, ds), ShouldNotBeNil)
+			}
+		})
+		Convey("A non-finite error should be reported", func() {
+			net, _ := NewLayered(1, []LayerSpec{{Size: 1, Func: DIRECT}})
+			var ds Dataset
+			ds.Add([]float64{1}, []float64{math.NaN()})
+			So((&LevenbergMarquardt{}).Train(net, ds), ShouldNotBeNil)
+		})
+		Convey("Output transforms should be rejected", func() {
+			net, _ := NewLayered(2, []LayerSpec{{Size: 2, Func: DIRECT}})
+			net.SetOutputTransform(SOFTMAX)
+			So((&LevenbergMarquardt{}).Train(net, Dataset{Inputs: [][]float64{{0, 0}}, Targets: [][]float64{{1, 0}}}), ShouldNotBeNil)
+		})
+	})
+}
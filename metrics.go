@@ -0,0 +1,292 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ClassMetrics measures how well one class is predicted
+type ClassMetrics struct {
+	Precision float64 `json:"precision"` // Fraction of predictions of the class which are right
+	Recall    float64 `json:"recall"`    // Fraction of samples of the class which are found
+	F1        float64 `json:"f1"`        // Harmonic mean of precision and recall
+	Support   int     `json:"support"`   // Number of samples of the class
+}
+
+// ClassificationReport measures how well a Network classifies a Dataset
+type ClassificationReport struct {
+	Accuracy float64        `json:"accuracy"`
+	Classes  []ClassMetrics `json:"classes"`
+
+	// Confusion[a][p] counts the samples of class a predicted as class p
+	Confusion [][]int `json:"confusion"`
+}
+
+// ROCPoint is one point on a receiver operating characteristic curve: the rates
+// of false and true positives when scores of at least Threshold are positive
+type ROCPoint struct {
+	FPR       float64 `json:"fpr"`
+	TPR       float64 `json:"tpr"`
+	Threshold float64 `json:"threshold"`
+}
+
+// RegressionReport measures how well a Network fits a Dataset
+type RegressionReport struct {
+	RMSE float64 `json:"rmse"` // Root mean squared error
+	MAE  float64 `json:"mae"`  // Mean absolute error
+	R2   float64 `json:"r2"`   // Coefficient of determination
+}
+
+// EvaluateClassification activates the Network for each sample in the Dataset
+// and compares the predicted class with the target class. With several outputs
+// the class is the index of the largest value. With one output there are two
+// classes, split at 0.5.
+func (n *Network) EvaluateClassification(ds Dataset) (ClassificationReport, error) {
+	if err := ds.check(n); err != nil {
+		return ClassificationReport{}, err
+	}
+
+	classes := n.outputCount
+	if classes == 1 {
+		classes = 2
+	}
+	predicted := make([]int, ds.Len())
+	actual := make([]int, ds.Len())
+	outputs := make([]float64, n.outputCount)
+	for i := range ds.Inputs {
+		n.activate(ds.Inputs[i], outputs)
+		predicted[i] = classOf(outputs)
+		actual[i] = classOf(ds.Targets[i])
+	}
+	return Classification(predicted, actual, classes)
+}
+
+// Returns the class given by a row of outputs or targets
+func classOf(values []float64) int {
+	if len(values) == 1 {
+		if values[0] >= 0.5 {
+			return 1
+		}
+		return 0
+	}
+	best := 0
+	for i, x := range values {
+		if x > values[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// Classification returns the report for predicted classes against the actual
+// ones. Classes are numbered from 0 to classes-1. Metrics which would divide by
+// zero are reported as 0. It fails if the slices differ in length or hold a
+// class out of range.
+func Classification(predicted, actual []int, classes int) (ClassificationReport, error) {
+	if len(predicted) != len(actual) {
+		return ClassificationReport{}, errors.New("neural: different numbers of predicted and actual classes")
+	}
+	if classes < 1 {
+		return ClassificationReport{}, errors.New("neural: classification needs at least one class")
+	}
+	for i := range predicted {
+		if predicted[i] < 0 || predicted[i] >= classes || actual[i] < 0 || actual[i] >= classes {
+			return ClassificationReport{}, fmt.Errorf("neural: sample %d has a class outside [0,%d)", i, classes)
+		}
+	}
+
+	r := ClassificationReport{
+		Classes:   make([]ClassMetrics, classes),
+		Confusion: make([][]int, classes),
+	}
+	for i := range r.Confusion {
+		r.Confusion[i] = make([]int, classes)
+	}
+
+	correct := 0
+	for i := range predicted {
+		r.Confusion[actual[i]][predicted[i]]++
+		if actual[i] == predicted[i] {
+			correct++
+		}
+	}
+	r.Accuracy = ratio(float64(correct), float64(len(predicted)))
+
+	for c := range r.Classes {
+		tp, predictedCount, actualCount := r.Confusion[c][c], 0, 0
+		for k := 0; k < classes; k++ {
+			predictedCount += r.Confusion[k][c]
+			actualCount += r.Confusion[c][k]
+		}
+		m := &r.Classes[c]
+		m.Support = actualCount
+		m.Precision = ratio(float64(tp), float64(predictedCount))
+		m.Recall = ratio(float64(tp), float64(actualCount))
+		m.F1 = ratio(2*m.Precision*m.Recall, m.Precision+m.Recall)
+	}
+	return r, nil
+}
+
+// ROC activates the Network for each sample in the Dataset and returns the ROC
+// curve and the area under it for one class, using the output for that class
+// as the score. With one output, class 1 uses the output and class 0 its
+// complement.
+func (n *Network) ROC(ds Dataset, class int) ([]ROCPoint, float64, error) {
+	if err := ds.check(n); err != nil {
+		return nil, 0, err
+	}
+	classes := n.outputCount
+	if classes == 1 {
+		classes = 2
+	}
+	if class < 0 || class >= classes {
+		return nil, 0, fmt.Errorf("neural: class %d is outside [0,%d)", class, classes)
+	}
+
+	scores := make([]float64, ds.Len())
+	positive := make([]bool, ds.Len())
+	outputs := make([]float64, n.outputCount)
+	for i := range ds.Inputs {
+		n.activate(ds.Inputs[i], outputs)
+		if n.outputCount == 1 {
+			scores[i] = outputs[0]
+			if class == 0 {
+				scores[i] = 1 - outputs[0]
+			}
+		} else {
+			scores[i] = outputs[class]
+		}
+		positive[i] = classOf(ds.Targets[i]) == class
+	}
+
+	points, err := ROCCurve(scores, positive)
+	if err != nil {
+		return nil, 0, err
+	}
+	return points, AUC(points), nil
+}
+
+// ROCCurve returns the ROC curve of scores against whether each sample is
+// positive. It starts at (0,0) and ends at (1,1) with one point per distinct
+// score between. If there are no positive or no negative samples, that rate is 0
+// throughout. It fails if the slices differ in length.
+func ROCCurve(scores []float64, positive []bool) ([]ROCPoint, error) {
+	if len(scores) != len(positive) {
+		return nil, errors.New("neural: different numbers of scores and labels")
+	}
+
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	positives, negatives := 0.0, 0.0
+	for _, p := range positive {
+		if p {
+			positives++
+		} else {
+			negatives++
+		}
+	}
+
+	points := []ROCPoint{{FPR: 0, TPR: 0, Threshold: math.Inf(1)}}
+	tp, fp := 0.0, 0.0
+	for k, i := range order {
+		if positive[i] {
+			tp++
+		} else {
+			fp++
+		}
+		// Samples with equal scores share a threshold, so only the last makes a point
+		if k+1 < len(order) && scores[order[k+1]] == scores[i] {
+			continue
+		}
+		points = append(points, ROCPoint{FPR: ratio(fp, negatives), TPR: ratio(tp, positives), Threshold: scores[i]})
+	}
+	return points, nil
+}
+
+// AUC returns the area under a ROC curve by the trapezoidal rule
+func AUC(points []ROCPoint) float64 {
+	area := 0.0
+	for i := 1; i < len(points); i++ {
+		area += (points[i].FPR - points[i-1].FPR) * (points[i].TPR + points[i-1].TPR) / 2
+	}
+	return area
+}
+
+// EvaluateRegression activates the Network for each sample in the Dataset and
+// measures the error of the outputs against the targets, over all outputs. R2
+// compares the squared error with the variance of each target about its mean.
+func (n *Network) EvaluateRegression(ds Dataset) (RegressionReport, error) {
+	if err := ds.check(n); err != nil {
+		return RegressionReport{}, err
+	}
+
+	means := make([]float64, n.outputCount)
+	for _, t := range ds.Targets {
+		for j, x := range t {
+			means[j] += x / float64(ds.Len())
+		}
+	}
+
+	var squared, absolute, variance float64
+	outputs := make([]float64, n.outputCount)
+	for i := range ds.Inputs {
+		n.activate(ds.Inputs[i], outputs)
+		for j, y := range outputs {
+			e := y - ds.Targets[i][j]
+			squared += e * e
+			absolute += math.Abs(e)
+			d := ds.Targets[i][j] - means[j]
+			variance += d * d
+		}
+	}
+
+	count := float64(ds.Len() * n.outputCount)
+	r := RegressionReport{
+		RMSE: math.Sqrt(ratio(squared, count)),
+		MAE:  ratio(absolute, count),
+	}
+	if variance > 0 {
+		r.R2 = 1 - squared/variance
+	}
+	return r, nil
+}
+
+// Returns a / b, or 0 when b is 0
+func ratio(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
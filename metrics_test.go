@@ -0,0 +1,134 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	Convey("Subject: Metrics", t, func() {
+
+		Convey("Given predicted and actual classes", func() {
+			predicted := []int{0, 0, 1, 1, 2, 2, 2, 0}
+			actual := []int{0, 1, 1, 1, 2, 0, 2, 0}
+			r, err := Classification(predicted, actual, 3)
+			So(err, ShouldBeNil)
+
+			Convey("Accuracy should count the right predictions", func() {
+				So(r.Accuracy, ShouldEqual, 6.0/8.0)
+			})
+			Convey("The confusion matrix should be indexed by actual then predicted", func() {
+				So(r.Confusion, ShouldResemble, [][]int{{2, 0, 1}, {1, 2, 0}, {0, 0, 2}})
+			})
+			Convey("Per class metrics should be correct", func() {
+				So(r.Classes[0].Precision, ShouldEqual, 2.0/3.0)
+				So(r.Classes[0].Recall, ShouldEqual, 2.0/3.0)
+				So(r.Classes[1].Precision, ShouldEqual, 1.0)
+				So(r.Classes[1].Recall, ShouldEqual, 2.0/3.0)
+				So(r.Classes[1].F1, ShouldAlmostEqual, 0.8)
+				So(r.Classes[2].Support, ShouldEqual, 2)
+			})
+			Convey("The report should encode as JSON", func() {
+				_, err := json.Marshal(r)
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("Bad classes should be rejected", func() {
+			_, err := Classification([]int{0, 1}, []int{0}, 2)
+			So(err, ShouldNotBeNil)
+			_, err = Classification([]int{0, 2}, []int{0, 1}, 2)
+			So(err, ShouldNotBeNil)
+			_, err = Classification([]int{0, 1}, []int{-1, 1}, 2)
+			So(err, ShouldNotBeNil)
+			_, err = ROCCurve([]float64{0.5}, []bool{true, false})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Given scores for positive and negative samples", func() {
+			curve := func(scores []float64, positive []bool) []ROCPoint {
+				points, err := ROCCurve(scores, positive)
+				So(err, ShouldBeNil)
+				return points
+			}
+			Convey("A perfect ranking should have an AUC of 1", func() {
+				points := curve([]float64{0.9, 0.8, 0.3, 0.1}, []bool{true, true, false, false})
+				So(AUC(points), ShouldEqual, 1.0)
+				So(points[len(points)-1].FPR, ShouldEqual, 1.0)
+				So(points[len(points)-1].TPR, ShouldEqual, 1.0)
+			})
+			Convey("A reversed ranking should have an AUC of 0", func() {
+				So(AUC(curve([]float64{0.1, 0.2, 0.8, 0.9}, []bool{true, true, false, false})), ShouldEqual, 0.0)
+			})
+			Convey("Tied scores should count as half", func() {
+				points := curve([]float64{0.5, 0.5}, []bool{true, false})
+				So(len(points), ShouldEqual, 2)
+				So(AUC(points), ShouldEqual, 0.5)
+			})
+			Convey("The AUC should match the fraction of correctly ordered pairs", func() {
+				So(AUC(curve([]float64{0.9, 0.7, 0.6, 0.4, 0.5}, []bool{true, false, true, false, true})), ShouldAlmostEqual, 4.0/6.0)
+			})
+		})
+
+		Convey("Given a Network and a Dataset", func() {
+			net, _ := NewGraph().Input("x").Output("y", DIRECT).Connect("x", "y", 3).Build()
+			var ds Dataset
+			ds.Add([]float64{0.1}, []float64{0})
+			ds.Add([]float64{0.2}, []float64{0})
+			ds.Add([]float64{0.3}, []float64{1})
+			ds.Add([]float64{0.4}, []float64{1})
+
+			Convey("A single output should classify at 0.5", func() {
+				r, err := net.EvaluateClassification(ds)
+				So(err, ShouldBeNil)
+				So(r.Accuracy, ShouldEqual, 0.75)
+				So(r.Confusion, ShouldResemble, [][]int{{1, 1}, {0, 2}})
+			})
+			Convey("The ROC of the output should be perfect", func() {
+				_, auc, err := net.ROC(ds, 1)
+				So(err, ShouldBeNil)
+				So(auc, ShouldEqual, 1.0)
+				_, auc, _ = net.ROC(ds, 0)
+				So(auc, ShouldEqual, 1.0)
+				_, _, err = net.ROC(ds, 2)
+				So(err, ShouldNotBeNil)
+			})
+			Convey("Regression metrics should measure the error", func() {
+				r, err := net.EvaluateRegression(ds)
+				So(err, ShouldBeNil)
+				// Errors are 0.3, 0.6, -0.1, 0.2
+				So(r.MAE, ShouldAlmostEqual, 0.3)
+				So(r.RMSE, ShouldAlmostEqual, math.Sqrt(0.125))
+				So(r.R2, ShouldAlmostEqual, 0.5)
+			})
+		})
+	})
+}
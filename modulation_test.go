@@ -0,0 +1,116 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+func TestModulation(t *testing.T) {
+	Convey("Subject: Modulatory nodes", t, func() {
+		// The input feeds the output through a plastic connection and drives a
+		// modulatory node, which modulates the output with the given weight
+		newNet := func(modWeight float64) (*Network, *PlasticConnection) {
+			in, out := NewNode(DIRECT, INPUT), NewNode(DIRECT, OUTPUT)
+			mod := NewNode(DIRECT, MODULATORY)
+			c := NewPlasticConnection(in, out, 0.5, Plasticity{Rule: HEBB, Eta: 0.1})
+			net := &Network{}
+			for _, x := range []Node{mod, out, in} {
+				net.AddNode(x)
+			}
+			net.AddConnection(NewConnection(mod, out, modWeight))
+			net.AddConnection(c)
+			net.AddConnection(NewConnection(in, mod, 1))
+			So(net.SortConnections(), ShouldBeNil)
+			return net, c
+		}
+
+		Convey("Modulatory nodes should sort after hidden nodes", func() {
+			net, _ := newNet(1)
+			So(net.nodes[len(net.nodes)-1].NodeType(), ShouldEqual, MODULATORY)
+			So(net.modulatory, ShouldEqual, 1)
+		})
+		Convey("The modulatory signal should not add to the input of its target", func() {
+			net, _ := newNet(5)
+			So(net.Activate([]float64{2})[0], ShouldEqual, 1.0)
+		})
+		Convey("The modulatory signal should scale the plasticity", func() {
+			net, c := newNet(1)
+			net.Activate([]float64{2})
+			So(c.Weight(), ShouldAlmostEqual, 0.5+math.Tanh(1)*0.1*2*1)
+		})
+		Convey("A negative modulatory signal should reverse the plasticity", func() {
+			net, c := newNet(-1)
+			net.Activate([]float64{2})
+			So(c.Weight(), ShouldBeLessThan, 0.5)
+		})
+		Convey("A zero modulatory signal should stop the plasticity", func() {
+			net, c := newNet(0)
+			net.Activate([]float64{2})
+			So(c.Weight(), ShouldEqual, 0.5)
+		})
+		Convey("Modulatory connections should have no gradient", func() {
+			net, _ := newNet(3)
+			grads := net.NewGradients()
+			net.BackpropLoss(SQUARED_ERROR, []float64{2}, []float64{0}, grads)
+			for i, c := range net.conns {
+				if c.From().NodeType() == MODULATORY || c.To().NodeType() == MODULATORY {
+					So(grads.Weights[i], ShouldEqual, 0)
+				}
+			}
+			result, err := GradCheck(net, SQUARED_ERROR, Sample{Inputs: []float64{2}, Targets: []float64{0}})
+			So(err, ShouldBeNil)
+			So(result.MaxError, ShouldBeLessThan, 1e-6)
+		})
+		Convey("A compiled Plan should ignore the modulatory signal", func() {
+			net, _ := newNet(5)
+			plan, err := net.Compile()
+			So(err, ShouldBeNil)
+			So(plan.Activate([]float64{2}), ShouldResemble, net.Activate([]float64{2}))
+		})
+		Convey("The Graph builder should declare modulatory nodes", func() {
+			net, err := NewGraph().Input("x").Modulatory("m", SIGMOID).Output("y", DIRECT).
+				Connect("x", "y", 1).Connect("x", "m", 1).Connect("m", "y", 2).Build()
+			So(err, ShouldBeNil)
+			So(net.Activate([]float64{0.25}), ShouldResemble, []float64{0.25})
+		})
+		Convey("Modulatory nodes should survive serialization", func() {
+			net, _ := newNet(1)
+			data, err := json.Marshal(net)
+			So(err, ShouldBeNil)
+			var restored Network
+			So(json.Unmarshal(data, &restored), ShouldBeNil)
+			So(restored.nodes[2].NodeType(), ShouldEqual, MODULATORY)
+			restored.Activate([]float64{2})
+			net.Activate([]float64{2})
+			So(restored.conns[1].Weight(), ShouldEqual, net.conns[1].Weight())
+		})
+	})
+}
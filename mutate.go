@@ -0,0 +1,63 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"github.com/boggo/random"
+)
+
+// MutateAggType replaces the AggType of the node with a different one, chosen
+// at random
+func MutateAggType(node Node) {
+	i := int(random.Next() * float64(len(AggTypes)-1))
+	if AggTypes[i] >= node.AggType() {
+		i++
+	}
+	node.SetAggType(AggTypes[i])
+}
+
+// MutateBias perturbs the bias of the node by a random amount between -power
+// and power
+func MutateBias(node Node, power float64) {
+	node.SetBias(node.Bias() + (random.Next()*2-1)*power)
+}
+
+// MutateResponse perturbs the response of the node by a random amount between
+// -power and power
+func MutateResponse(node Node, power float64) {
+	node.SetResponse(node.Response() + (random.Next()*2-1)*power)
+}
+
+// MutatePlasticity perturbs the learning rate and ABCD coefficients of the
+// connection by random amounts between -power and power
+func MutatePlasticity(conn *PlasticConnection, power float64) {
+	p := conn.Plasticity()
+	for _, x := range []*float64{&p.Eta, &p.A, &p.B, &p.C, &p.D} {
+		*x += (random.Next()*2 - 1) * power
+	}
+	conn.SetPlasticity(p)
+}
@@ -0,0 +1,75 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestMutate(t *testing.T) {
+	Convey("Subject: Mutation", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+
+		Convey("MutateAggType should always choose a different AggType", func() {
+			n := NewNode(SIGMOID, HIDDEN)
+			seen := make(map[AggType]bool)
+			for i := 0; i < 200; i++ {
+				before := n.AggType()
+				MutateAggType(n)
+				So(n.AggType(), ShouldNotEqual, before)
+				seen[n.AggType()] = true
+			}
+			So(len(seen), ShouldEqual, len(AggTypes))
+		})
+
+		Convey("MutateBias and MutateResponse should stay within the power", func() {
+			n := NewNode(SIGMOID, HIDDEN)
+			for i := 0; i < 100; i++ {
+				n.SetBias(0)
+				n.SetResponse(1)
+				MutateBias(n, 0.5)
+				MutateResponse(n, 0.5)
+				So(n.Bias(), ShouldBeBetween, -0.5, 0.5)
+				So(n.Response(), ShouldBeBetween, 0.5, 1.5)
+			}
+		})
+
+		Convey("MutatePlasticity should perturb every coefficient within the power", func() {
+			c := NewPlasticConnection(NewNode(DIRECT, INPUT), NewNode(SIGMOID, OUTPUT), 1, Plasticity{Rule: ABCD, Limit: 3})
+			MutatePlasticity(c, 0.1)
+			p := c.Plasticity()
+			for _, x := range []float64{p.Eta, p.A, p.B, p.C, p.D} {
+				So(x, ShouldNotEqual, 0)
+				So(x, ShouldBeBetween, -0.1, 0.1)
+			}
+			So(p.Rule, ShouldEqual, ABCD)
+			So(p.Limit, ShouldEqual, 3)
+		})
+	})
+}
@@ -32,7 +32,9 @@ import (
 	"sort"
 )
 
-// A Network of Nodes and Connections
+// A Network of Nodes and Connections. The values computed during activation
+// are kept in the Nodes, so a Network must not be activated from several
+// goroutines at once. Compile it to a Plan to share it between goroutines.
 type Network struct {
 	nodes nodeList
 	conns connList
@@ -41,6 +43,17 @@ type Network struct {
 	inputCount  int
 	outputCount int
 	hiddenCount int
+	modulatory  int // Number of MODULATORY nodes
+	plastic     int // Number of connections whose weight changes as the Network runs
+
+	transform    OutputTransform
+	inputScaler  *Scaler
+	outputScaler *Scaler
+	integrator   Integrator
+	ctrnn        *ctrnn // State when run as a CTRNN
+
+	dropout     float64        // Probability of dropping each hidden node while training
+	dropoutRand func() float64 // Uniform random numbers in [0,1) for dropout
 }
 
 // Creates a new, empty Network
@@ -99,7 +112,8 @@ func NewNetwork(numInput, numHidden, numOutput int) *Network {
 }
 
 // Adds a Node to the Network. The nodes are kept loosely sorted in order
-// of NodeType: Bias, Input, Output, Hidden
+// of NodeType: Bias, Input, Output, Hidden, Modulatory. Nodes of the same type keep the
+// order in which they were added.
 func (n *Network) AddNode(node Node) {
 
 	// Ensure the network has a nodes list
@@ -109,7 +123,7 @@ func (n *Network) AddNode(node Node) {
 
 	// Add the node to the slice
 	n.nodes = append(n.nodes, node)
-	sort.Sort(n.nodes)
+	sort.Stable(n.nodes)
 
 	// Update the internal counts
 	switch node.NodeType() {
@@ -121,6 +135,8 @@ func (n *Network) AddNode(node Node) {
 		n.outputCount++
 	case HIDDEN:
 		n.hiddenCount++
+	case MODULATORY:
+		n.modulatory++
 	}
 }
 
@@ -135,11 +151,58 @@ func (n *Network) AddConnection(conn Connection) {
 
 	// Add the connection
 	n.conns = append(n.conns, conn)
+	if _, ok := conn.(adaptiveConnection); ok {
+		n.plastic++
+	}
+}
+
+// SortConnections reorders the Network's connections so that every connection
+// into a node is activated before any connection out of it. It returns ErrCycle,
+// leaving the order unchanged, if the connections form a cycle.
+func (n *Network) SortConnections() error {
+	sorted, err := sortConnections(n.conns)
+	if err != nil {
+		return err
+	}
+	n.conns = sorted
+	return nil
 }
 
 // Activates the Network. Takes a slice of float64 values as input and outputs
-// a slice of float64 values. Note: The network is updated during this method.
+// a slice of float64 values. Note: The network is updated during this method,
+// including the weights of any PlasticConnections.
 func (n *Network) Activate(inputs []float64) (outputs []float64) {
+	outputs = make([]float64, n.outputCount)
+	n.activate(inputs, outputs)
+	n.adapt()
+	return
+}
+
+// ActivateInto activates the Network like Activate, but writes the outputs into
+// a buffer owned by the caller so that no memory is allocated. It returns
+// ErrInputSize or ErrOutputSize if the slices do not match the Network.
+func (n *Network) ActivateInto(inputs, outputs []float64) error {
+	if len(inputs) != n.inputCount {
+		return ErrInputSize
+	}
+	if len(outputs) != n.outputCount {
+		return ErrOutputSize
+	}
+	n.activate(inputs, outputs)
+	n.adapt()
+	return nil
+}
+
+// Activates the Network with inputs and writes the transformed outputs into outputs
+func (n *Network) activate(inputs, outputs []float64) {
+	n.forward(inputs, outputs)
+	transformOutputs(n.transform, outputs)
+	n.unscaleOutputs(outputs)
+}
+
+// Activates the Network with inputs, after the input scaler, and writes the
+// activations of the output nodes into outputs
+func (n *Network) forward(inputs, outputs []float64) {
 
 	// Reset the network
 	for i, _ := range n.nodes {
@@ -152,20 +215,23 @@ func (n *Network) Activate(inputs []float64) (outputs []float64) {
 
 	// Set the inputs
 	for i, _ := range inputs {
-		n.nodes[i+inputOffset].Combine(inputs[i])
+		n.nodes[i+inputOffset].Combine(n.scaleInput(i, inputs[i]))
 	}
 
-	// Activate all the connections
+	// Activate all the connections. Those from modulatory nodes are kept apart
+	// from the input of their targets.
 	for i, _ := range n.conns {
-		n.conns[i].activate()
+		if c := n.conns[i]; n.modulatory > 0 && isModulatory(c) {
+			modulate(c.To(), c.From().Activate()*c.Weight()*gate(c))
+		} else {
+			c.activate()
+		}
 	}
 
 	// Return the outputs
-	outputs = make([]float64, n.outputCount)
-	for i := 0; i < n.outputCount; i++ {
+	for i := range outputs {
 		outputs[i] = n.nodes[i+outputOffset].Activate()
 	}
-	return
 }
 
 func (n *Network) Dump() {
@@ -121,6 +121,49 @@ func TestNetwork(t *testing.T) {
 				})
 			})
 		})
+		Convey("Given connections added out of order", func() {
+			bias := NewDirectNode(BIAS)
+			in1 := NewDirectNode(INPUT)
+			hid1 := NewSigmoidNode(HIDDEN)
+			out1 := NewSigmoidNode(OUTPUT)
+			net := &Network{}
+			net.AddNode(bias)
+			net.AddNode(in1)
+			net.AddNode(hid1)
+			net.AddNode(out1)
+			net.AddConnection(NewConnection(hid1, out1, 0.9))
+			net.AddConnection(NewConnection(bias, hid1, 0.1))
+			net.AddConnection(NewConnection(in1, hid1, 0.5))
+			Convey("SortConnections should put the hidden inputs first", func() {
+				So(net.SortConnections(), ShouldBeNil)
+				So(net.conns[2].From(), ShouldEqual, hid1)
+			})
+			Convey("SortConnections should reject a cycle", func() {
+				net.AddConnection(NewConnection(out1, hid1, 0.1))
+				So(net.SortConnections(), ShouldEqual, ErrCycle)
+				So(len(net.conns), ShouldEqual, 4)
+			})
+		})
+		Convey("Given a caller provided output buffer", func() {
+			net := NewNetwork(3, 4, 2)
+			inputs := []float64{0.1, 0.2, 0.3}
+			outputs := make([]float64, 2)
+			Convey("ActivateInto should match Activate", func() {
+				So(net.ActivateInto(inputs, outputs), ShouldBeNil)
+				So(outputs, ShouldResemble, net.Activate(inputs))
+			})
+			Convey("Wrongly sized buffers should be rejected", func() {
+				So(net.ActivateInto(inputs[:2], outputs), ShouldEqual, ErrInputSize)
+				So(net.ActivateInto(inputs, outputs[:1]), ShouldEqual, ErrOutputSize)
+			})
+			Convey("ActivateInto should not allocate", func() {
+				net.nodes[len(net.nodes)-1].SetAggType(MEDIAN)
+				allocs := testing.AllocsPerRun(100, func() {
+					net.ActivateInto(inputs, outputs)
+				})
+				So(allocs, ShouldEqual, 0)
+			})
+		})
 		Convey("Given a new Network specification", func() {
 			Convey("It should have the right number of components", func() {
 				net := NewNetwork(2, 2, 2)
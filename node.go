@@ -40,6 +40,7 @@ const (
 	INPUT
 	OUTPUT
 	HIDDEN
+	MODULATORY // Scales the plasticity of the connections into its targets
 )
 
 // FuncType to identify activation function
@@ -49,10 +50,12 @@ const (
 	DIRECT FuncType = iota
 	SIGMOID
 	STEEPENED_SIGMOID
+	RELU
+	TANH
 )
 
 var (
-	FuncTypes = []FuncType{DIRECT, SIGMOID, STEEPENED_SIGMOID}
+	FuncTypes = []FuncType{DIRECT, SIGMOID, STEEPENED_SIGMOID, RELU, TANH}
 )
 
 // Node interface
@@ -62,6 +65,14 @@ type Node interface {
 	Activate() float64
 	NodeType() NodeType
 	FuncType() FuncType
+	AggType() AggType
+	SetAggType(aggType AggType)
+	Bias() float64
+	SetBias(bias float64)
+	Response() float64
+	SetResponse(response float64)
+	TimeConstant() float64
+	SetTimeConstant(tau float64) error
 }
 
 // List of Nodes
@@ -82,10 +93,21 @@ type node struct {
 	input    float64
 	nodeType NodeType
 	funcType FuncType
+	aggType  AggType
+	bias     float64 // Added to the scaled input before activation
+	response float64 // Scales the input before activation
+	tau      float64 // Time constant of the node's state when run as a CTRNN
+	scale    float64 // Factor dropout applies to the activation: 0 if dropped, else 1/(1-rate)
+
+	modulation float64 // Sum of the modulatory signals received since the last reset
+	modulated  bool    // Whether any modulatory signal was received since the last reset
+
+	count  int       // Number of values combined since the last reset, unless summing
+	values []float64 // Sorted values combined since the last reset, for MEDIAN
 }
 
 func newNode(nodeType NodeType, funcType FuncType) node {
-	n := node{nodeType: nodeType, funcType: funcType}
+	n := node{nodeType: nodeType, funcType: funcType, response: 1.0, tau: 1.0, scale: 1.0}
 	if nodeType == BIAS {
 		n.input = 1.0
 	}
@@ -102,6 +124,8 @@ func (n node) String() string {
 		ntype = "OUTPUT "
 	case HIDDEN:
 		ntype = "HIDDEN "
+	case MODULATORY:
+		ntype = "MODULATORY"
 	default:
 		ntype = "UNKNOWN"
 	}
@@ -113,6 +137,10 @@ func (n node) String() string {
 		ftype = "SIGMOID          "
 	case STEEPENED_SIGMOID:
 		ftype = "STEEPEND SIGMOID"
+	case RELU:
+		ftype = "RELU            "
+	case TANH:
+		ftype = "TANH            "
 	default:
 		ftype = "UNKNOWN         "
 	}
@@ -127,11 +155,16 @@ func (n *node) Reset() {
 	} else {
 		n.input = 0
 	}
+	n.count = 0
+	n.values = n.values[:0]
+	n.modulation, n.modulated = 0, false
 }
 
 // Combines the new value with the existing input value of the Node. For Bias
 // nodes this throws an error. For input nodes, this just replaces the input value.
-// For all other Nodes, this adds the new value to the input value.
+// For all other Nodes, this aggregates the new value with the values combined
+// since the last reset, according to the AggType. By default this adds the new
+// value to the input value.
 func (n *node) Combine(value float64) {
 	switch n.NodeType() {
 	case BIAS:
@@ -139,7 +172,12 @@ func (n *node) Combine(value float64) {
 	case INPUT:
 		n.input = value
 	default:
-		n.input += value
+		if n.aggType == SUM {
+			n.input += value
+			return
+		}
+		n.input, n.values = aggregateInto(n.aggType, n.input, n.count, n.values, value)
+		n.count++
 	}
 }
 
@@ -153,6 +191,103 @@ func (n node) FuncType() FuncType {
 	return n.funcType
 }
 
+// AggType returns how the Node combines its incoming values
+func (n node) AggType() AggType {
+	return n.aggType
+}
+
+// SetAggType changes how the Node combines its incoming values
+func (n *node) SetAggType(aggType AggType) {
+	n.aggType = aggType
+}
+
+// Bias returns the value added to the Node's scaled input before activation
+func (n node) Bias() float64 {
+	return n.bias
+}
+
+// SetBias replaces the value added to the Node's scaled input before activation
+func (n *node) SetBias(bias float64) {
+	n.bias = bias
+}
+
+// Response returns the factor applied to the Node's input before activation
+func (n node) Response() float64 {
+	return n.response
+}
+
+// SetResponse replaces the factor applied to the Node's input before activation
+func (n *node) SetResponse(response float64) {
+	n.response = response
+}
+
+// TimeConstant returns how slowly the Node's state responds to its inputs when
+// the Network is advanced as a CTRNN
+func (n node) TimeConstant() float64 {
+	return n.tau
+}
+
+// SetTimeConstant replaces the time constant of the Node's state. It returns
+// ErrTimeConstant, leaving the time constant unchanged, unless tau is positive.
+func (n *node) SetTimeConstant(tau float64) error {
+	if !(tau > 0) {
+		return ErrTimeConstant
+	}
+	n.tau = tau
+	return nil
+}
+
+// signal is the value passed to the activation function: bias + response * input.
+// With the default bias of 0 and response of 1 this is just the input.
+func (n *node) signal() float64 {
+	return n.bias + n.response*n.input
+}
+
+// keep is the factor dropout applies to the activation: 0 if the node is
+// dropped, otherwise 1/(1-rate) so the expected activation is unchanged
+func (n *node) keep() float64 {
+	return n.scale
+}
+
+// setDropout marks the node as dropped or kept for a training pass, working
+// out the factor here so activation need not
+func (n *node) setDropout(rate float64, dropped bool) {
+	switch {
+	case dropped:
+		n.scale = 0
+	case rate == 0:
+		n.scale = 1
+	default:
+		n.scale = 1 / (1 - rate)
+	}
+}
+
+// modulate adds a modulatory signal, which is kept apart from the input
+func (n *node) modulate(value float64) {
+	n.modulation += value
+	n.modulated = true
+}
+
+// plasticityScale is the factor applied to the plasticity of the connections
+// into the node: tanh(m/2) for a modulatory signal m, or 1 if the node
+// received none
+func (n node) plasticityScale() float64 {
+	if !n.modulated {
+		return 1
+	}
+	return math.Tanh(n.modulation / 2)
+}
+
+// NewNodeAgg returns the appropriate Node based on FuncType, combining its
+// incoming values according to aggType
+func NewNodeAgg(funcType FuncType, nodeType NodeType, aggType AggType) Node {
+	n := NewNode(funcType, nodeType)
+	if n != nil {
+		n.SetAggType(aggType)
+	}
+	return n
+}
+
 // NewNode returns the appropriate Node based on FuncType
 func NewNode(funcType FuncType, nodeType NodeType) Node {
 	switch funcType {
@@ -162,6 +297,10 @@ func NewNode(funcType FuncType, nodeType NodeType) Node {
 		return NewSigmoidNode(nodeType)
 	case STEEPENED_SIGMOID:
 		return NewSteepenedSigmoidNode(nodeType)
+	case RELU:
+		return NewReluNode(nodeType)
+	case TANH:
+		return NewTanhNode(nodeType)
 	}
 
 	// Unknown FuncType, return nil
@@ -179,9 +318,10 @@ func NewDirectNode(nodeType NodeType) *DirectNode {
 	return &DirectNode{node: newNode(nodeType, DIRECT)}
 }
 
-// Activate returns the input value without transformation
-func (n DirectNode) Activate() float64 {
-	return n.input
+// Activate returns the input value, after bias and response, without
+// transformation
+func (n *DirectNode) Activate() float64 {
+	return n.keep() * n.signal()
 }
 
 // SigmoidNode is an implementation of Node which returns its input value transformed
@@ -200,8 +340,8 @@ func NewSigmoidNode(nodeType NodeType) *SigmoidNode {
 //                          -------
 //                               -t
 //                          1 + e
-func (n SigmoidNode) Activate() float64 {
-	return 1.0 / (1.0 + math.Exp(-n.input))
+func (n *SigmoidNode) Activate() float64 {
+	return n.keep() / (1.0 + math.Exp(-n.signal()))
 }
 
 // SigmoidNode is an implementation of Node which returns its input value transformed
@@ -220,6 +360,42 @@ func NewSteepenedSigmoidNode(nodeType NodeType) *SteepenedSigmoidNode {
 //                          -------
 //                               -4.9t
 //                          1 + e
-func (n SteepenedSigmoidNode) Activate() float64 {
-	return 1.0 / (1.0 + math.Exp(-4.9*n.input))
+func (n *SteepenedSigmoidNode) Activate() float64 {
+	return n.keep() / (1.0 + math.Exp(-4.9*n.signal()))
+}
+
+// ReluNode is an implementation of Node which returns its input value transformed
+// by the rectified linear function.
+type ReluNode struct {
+	node
+}
+
+// NewReluNode returns a pointer to a new Relu Node
+func NewReluNode(nodeType NodeType) *ReluNode {
+	return &ReluNode{node: newNode(nodeType, RELU)}
+}
+
+// Activate returns the input value transformed by the rectified linear function:
+// max(0, t)
+func (n *ReluNode) Activate() float64 {
+	if x := n.signal(); x > 0 {
+		return n.keep() * x
+	}
+	return 0
+}
+
+// TanhNode is an implementation of Node which returns its input value transformed
+// by the hyperbolic tangent function.
+type TanhNode struct {
+	node
+}
+
+// NewTanhNode returns a pointer to a new Tanh Node
+func NewTanhNode(nodeType NodeType) *TanhNode {
+	return &TanhNode{node: newNode(nodeType, TANH)}
+}
+
+// Activate returns the input value transformed by the hyperbolic tangent function
+func (n *TanhNode) Activate() float64 {
+	return n.keep() * math.Tanh(n.signal())
 }
@@ -150,6 +150,14 @@ func TestNode(t *testing.T) {
 				node = NewNode(STEEPENED_SIGMOID, INPUT)
 				So(node.FuncType(), ShouldEqual, STEEPENED_SIGMOID)
 			})
+			Convey("RELU should produce ReluNode", func() {
+				node = NewNode(RELU, INPUT)
+				So(node.FuncType(), ShouldEqual, RELU)
+			})
+			Convey("TANH should produce TanhNode", func() {
+				node = NewNode(TANH, INPUT)
+				So(node.FuncType(), ShouldEqual, TANH)
+			})
 		})
 
 		Convey("Given a new Direct Node", func() {
@@ -245,6 +253,42 @@ func TestNode(t *testing.T) {
 			})
 		})
 
+		Convey("Given a new Relu Node", func() {
+			h := NewReluNode(HIDDEN)
+			So(h.FuncType(), ShouldEqual, RELU)
+
+			Convey("Activate() should clip negative values", func() {
+				h.Combine(-2.0)
+				So(h.Activate(), ShouldEqual, 0.0)
+				h.Combine(3.0)
+				So(h.Activate(), ShouldEqual, 1.0)
+			})
+		})
+
+		Convey("Given a new Tanh Node", func() {
+			h := NewTanhNode(HIDDEN)
+			So(h.FuncType(), ShouldEqual, TANH)
+
+			Convey("Activate() should return the hyperbolic tangent", func() {
+				So(h.Activate(), ShouldEqual, 0.0)
+				h.Combine(1.0)
+				So(h.Activate(), ShouldEqual, math.Tanh(1.0))
+			})
+		})
+
+		Convey("Given a Node with a bias and response", func() {
+			h := NewSigmoidNode(HIDDEN)
+			So(h.Bias(), ShouldEqual, 0.0)
+			So(h.Response(), ShouldEqual, 1.0)
+			h.SetBias(0.5)
+			h.SetResponse(2.0)
+
+			Convey("Activate() should apply them before the function", func() {
+				h.Combine(0.25)
+				So(h.Activate(), ShouldEqual, 1.0/(1.0+math.Exp(-1.0)))
+			})
+		})
+
 	})
 
 }
@@ -0,0 +1,124 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"math"
+)
+
+// Optimizer turns the gradients of each batch into changes to a Network
+type Optimizer interface {
+
+	// Step adjusts the Network given the mean gradients of a batch, the
+	// learning rate and the loss of the batch before the step
+	Step(n *Network, grads *Gradients, rate, loss float64)
+}
+
+// SGD is plain gradient descent: each parameter moves against its gradient by
+// the learning rate times the gradient. It is the Trainer's default.
+type SGD struct{}
+
+// Step calls ApplyGradients
+func (SGD) Step(n *Network, grads *Gradients, rate, loss float64) {
+	n.ApplyGradients(grads, rate)
+}
+
+// RPROP is iRPROP+, resilient backpropagation with weight backtracking. Each
+// parameter has its own step size, which grows while the sign of its gradient
+// stays the same and shrinks when the sign flips. If the loss went up, a step
+// which flipped its gradient's sign is undone. Only the signs of the gradients
+// matter, so the learning rate is ignored. A Trainer only accepts it with a
+// BatchSize covering the whole Dataset. The step sizes belong to the last
+// Network stepped and start afresh for another. Zero fields take the usual
+// defaults shown.
+type RPROP struct {
+	Increase    float64 // Factor for growing a step size, 1.2
+	Decrease    float64 // Factor for shrinking a step size, 0.5
+	InitialStep float64 // Starting step size, 0.1
+	MaxStep     float64 // Largest step size, 50
+	MinStep     float64 // Smallest step size, 1e-6
+
+	net      *Network   // Network the state below belongs to
+	steps    *Gradients // Step size of each parameter
+	prevGrad *Gradients // Gradient of each parameter at the last step
+	prevMove *Gradients // Change to each parameter at the last step
+	prevLoss float64
+}
+
+// Step takes one iRPROP+ step for every connection weight and for the bias and
+// response of every hidden and output node
+func (r *RPROP) Step(n *Network, grads *Gradients, rate, loss float64) {
+	if r.net != n || len(r.steps.Weights) != len(grads.Weights) {
+		r.net = n
+		r.steps, r.prevGrad, r.prevMove = n.NewGradients(), n.NewGradients(), n.NewGradients()
+		for _, x := range [][]float64{r.steps.Weights, r.steps.Bias, r.steps.Response} {
+			for i := range x {
+				x[i] = orDefault(r.InitialStep, 0.1)
+			}
+		}
+		r.prevLoss = math.Inf(1)
+	}
+	worse := loss > r.prevLoss
+	r.prevLoss = loss
+
+	for i, c := range n.conns {
+		c.SetWeight(r.update(c.Weight(), grads.Weights[i], &r.steps.Weights[i], &r.prevGrad.Weights[i], &r.prevMove.Weights[i], worse))
+	}
+	for i, x := range n.nodes {
+		if x.NodeType() == HIDDEN || x.NodeType() == OUTPUT {
+			x.SetBias(r.update(x.Bias(), grads.Bias[i], &r.steps.Bias[i], &r.prevGrad.Bias[i], &r.prevMove.Bias[i], worse))
+			x.SetResponse(r.update(x.Response(), grads.Response[i], &r.steps.Response[i], &r.prevGrad.Response[i], &r.prevMove.Response[i], worse))
+		}
+	}
+}
+
+// Returns a parameter after one iRPROP+ step, updating its step size, last
+// gradient and last change
+func (r *RPROP) update(value, grad float64, step, prevGrad, prevMove *float64, worse bool) float64 {
+	switch product := grad * *prevGrad; {
+	case product > 0:
+		*step = math.Min(*step*orDefault(r.Increase, 1.2), orDefault(r.MaxStep, 50))
+	case product < 0:
+		*step = math.Max(*step*orDefault(r.Decrease, 0.5), orDefault(r.MinStep, 1e-6))
+		if worse {
+			value -= *prevMove
+		}
+		*prevGrad, *prevMove = 0, 0
+		return value
+	}
+	*prevMove = -sign(grad) * *step
+	*prevGrad = grad
+	return value + *prevMove
+}
+
+// Returns x, or def if x is 0
+func orDefault(x, def float64) float64 {
+	if x == 0 {
+		return def
+	}
+	return x
+}
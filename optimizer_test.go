@@ -0,0 +1,103 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestOptimizer(t *testing.T) {
+	Convey("Subject: Optimizers", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+		newNet := func() *Network {
+			random.Reseed(0)
+			net, _ := NewLayered(2, []LayerSpec{{Size: 4, Func: TANH}, {Size: 1, Func: SIGMOID}})
+			return net
+		}
+
+		Convey("SGD should match ApplyGradients", func() {
+			a, b := newNet(), newNet()
+			grads := a.NewGradients()
+			a.BackpropLoss(SQUARED_ERROR, []float64{1, 0}, []float64{1}, grads)
+			a.ApplyGradients(grads, 0.5)
+			SGD{}.Step(b, grads, 0.5, 0)
+			for i := range a.conns {
+				So(b.conns[i].Weight(), ShouldEqual, a.conns[i].Weight())
+			}
+		})
+
+		Convey("Given an RPROP step", func() {
+			r := &RPROP{}
+
+			Convey("The first step should move against the gradient", func() {
+				step, prevGrad, prevMove := 0.1, 0.0, 0.0
+				So(r.update(1, 2, &step, &prevGrad, &prevMove, false), ShouldEqual, 0.9)
+			})
+			Convey("The step should grow while the sign holds", func() {
+				step, prevGrad, prevMove := 0.1, 0.0, 0.0
+				r.update(1, 2, &step, &prevGrad, &prevMove, false)
+				So(r.update(0.9, 3, &step, &prevGrad, &prevMove, false), ShouldAlmostEqual, 0.78)
+				So(step, ShouldAlmostEqual, 0.12)
+			})
+			Convey("A flipped sign should shrink the step and undo it if the loss rose", func() {
+				step, prevGrad, prevMove := 0.1, 0.0, 0.0
+				r.update(1, 2, &step, &prevGrad, &prevMove, false)
+				So(r.update(0.9, -1, &step, &prevGrad, &prevMove, true), ShouldEqual, 1.0)
+				So(step, ShouldEqual, 0.05)
+				So(prevGrad, ShouldEqual, 0)
+			})
+			Convey("A flipped sign should keep the step if the loss fell", func() {
+				step, prevGrad, prevMove := 0.1, 0.0, 0.0
+				r.update(1, 2, &step, &prevGrad, &prevMove, false)
+				So(r.update(0.9, -1, &step, &prevGrad, &prevMove, false), ShouldEqual, 0.9)
+			})
+		})
+
+		Convey("RPROP should learn XOR quickly", func() {
+			net := newNet()
+			trainer := &Trainer{Epochs: 200, Optimizer: &RPROP{}}
+			So(trainer.Train(net, xorDataset()), ShouldBeNil)
+			loss, _ := net.Evaluate(xorDataset(), SQUARED_ERROR)
+			So(loss, ShouldBeLessThan, 0.001)
+		})
+		Convey("RPROP should refuse mini-batches", func() {
+			trainer := &Trainer{Epochs: 1, BatchSize: 2, Optimizer: &RPROP{}}
+			So(trainer.Train(newNet(), xorDataset()), ShouldNotBeNil)
+		})
+		Convey("RPROP should start afresh on another Network", func() {
+			r := &RPROP{}
+			a, b := newNet(), newNet()
+			r.Step(a, a.NewGradients(), 0, 1)
+			r.steps.Weights[0] = 7
+			r.Step(b, b.NewGradients(), 0, 1)
+			So(r.net, ShouldEqual, b)
+			So(r.steps.Weights[0], ShouldEqual, 0.1)
+		})
+	})
+}
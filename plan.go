@@ -0,0 +1,500 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"errors"
+	"math"
+	"sync"
+)
+
+// Errors returned when the buffers passed to ActivateInto are the wrong size,
+// or when a state passed to a Plan was not made for it
+var (
+	ErrInputSize  = errors.New("neural: wrong number of inputs")
+	ErrOutputSize = errors.New("neural: wrong size of output buffer")
+	ErrState      = errors.New("neural: state does not match the plan")
+)
+
+// Float is the type of value a compiled Plan works with
+type Float interface {
+	~float32 | ~float64
+}
+
+// Plan is the compiled form of a Network which works in float64
+type Plan = PlanOf[float64]
+
+// Plan32 is the compiled form of a Network which works in float32, halving the
+// memory used by its weights and values at the cost of precision
+type Plan32 = PlanOf[float32]
+
+// ActivationState holds the values computed while activating a Plan
+type ActivationState = StateOf[float64]
+
+// ActivationState32 holds the values computed while activating a Plan32
+type ActivationState32 = StateOf[float32]
+
+// PlanOf is a compiled form of a Network for fast activation. It holds the
+// topology as flat index arrays, with the weights, bias, response and functions
+// copied from the Network when it was compiled. Later changes to the Network
+// are not seen by the Plan, and the Plan itself never changes.
+//
+// The values computed during an activation are kept in an ActivationState
+// rather than in the Plan, so a single Plan may be activated from many
+// goroutines at once. Each call takes a state from a pool belonging to the
+// Plan, or the caller may supply its own with ActivateState.
+type PlanOf[T Float] struct {
+	nodeCount    int
+	inputOffset  int
+	inputCount   int
+	outputOffset int
+	outputCount  int
+	transform    OutputTransform
+
+	// Scalers for the inputs and outputs, nil when there are none
+	inShift, inScale   []T
+	outShift, outScale []T
+
+	// Per node
+	nodeTypes []NodeType
+	funcs     []FuncType
+	aggs      []AggType
+	bias      []T
+	response  []T
+
+	// Hidden and output nodes in activation order. The connections into
+	// order[k] are from[start[k]:start[k+1]] with the matching weights.
+	order   []int
+	start   []int
+	from    []int
+	weights []T
+	maxIn   int
+
+	states sync.Pool
+}
+
+// StateOf holds the values computed while activating a PlanOf. A state may be
+// reused for any number of activations but by one goroutine at a time.
+type StateOf[T Float] struct {
+	acts   []T // Activation of each node
+	values []T // Incoming values of a node which aggregates by MEDIAN
+	batch  []T // Activation of each node for each row of a batch
+
+	plan *PlanOf[T] // Plan the state was made for
+}
+
+// Compile returns a Plan which activates the Network. Each node is activated
+// once, after all of its incoming connections, so the order in which the
+// connections were added does not matter. Connections into bias and input nodes
+// are ignored. It returns ErrCycle if the connections form a cycle.
+func (n *Network) Compile() (*Plan, error) {
+	size := len(n.nodes)
+	p := &Plan{
+		nodeCount:    size,
+		inputOffset:  n.biasCount,
+		inputCount:   n.inputCount,
+		outputOffset: n.biasCount + n.inputCount,
+		outputCount:  n.outputCount,
+		transform:    n.transform,
+		nodeTypes:    make([]NodeType, size),
+		funcs:        make([]FuncType, size),
+		aggs:         make([]AggType, size),
+		bias:         make([]float64, size),
+		response:     make([]float64, size),
+	}
+	if s := n.inputScaler; s != nil {
+		p.inShift, p.inScale = convertSlice[float64](s.Shift), convertSlice[float64](s.Scale)
+	}
+	if s := n.outputScaler; s != nil {
+		p.outShift, p.outScale = convertSlice[float64](s.Shift), convertSlice[float64](s.Scale)
+	}
+
+	index := make(map[Node]int, size)
+	for i, x := range n.nodes {
+		index[x] = i
+		p.nodeTypes[i] = x.NodeType()
+		p.funcs[i] = x.FuncType()
+		p.aggs[i] = x.AggType()
+		p.bias[i] = x.Bias()
+		p.response[i] = x.Response()
+	}
+
+	// Group the connections by target, keeping their order
+	incoming := make([][]Connection, size)
+	outgoing := make([][]int, size)
+	pending := make([]int, size)
+	for _, c := range n.conns {
+		from, ok := index[c.From()]
+		if !ok {
+			return nil, errors.New("neural: connection starts outside the network")
+		}
+		to, ok := index[c.To()]
+		if !ok {
+			return nil, errors.New("neural: connection ends outside the network")
+		}
+		if t := p.nodeTypes[to]; t == BIAS || t == INPUT || p.nodeTypes[from] == MODULATORY {
+			continue
+		}
+		if gaterOf(c) != nil {
+			return nil, errors.New("neural: gated connections cannot be compiled")
+		}
+		incoming[to] = append(incoming[to], c)
+		outgoing[from] = append(outgoing[from], to)
+		pending[to]++
+	}
+
+	// Order the hidden and output nodes so each comes after its sources
+	var ready []int
+	for i := range n.nodes {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	activated := 0
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		activated++
+		if t := p.nodeTypes[i]; t != BIAS && t != INPUT {
+			p.order = append(p.order, i)
+		}
+		for _, j := range outgoing[i] {
+			pending[j]--
+			if pending[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+	if activated < size {
+		return nil, ErrCycle
+	}
+
+	// Flatten the incoming connections
+	p.start = make([]int, 0, len(p.order)+1)
+	p.start = append(p.start, 0)
+	for _, j := range p.order {
+		for _, c := range incoming[j] {
+			p.from = append(p.from, index[c.From()])
+			p.weights = append(p.weights, c.Weight())
+		}
+		p.start = append(p.start, len(p.from))
+		if len(incoming[j]) > p.maxIn {
+			p.maxIn = len(incoming[j])
+		}
+	}
+
+	p.states.New = func() interface{} { return p.NewState() }
+	return p, nil
+}
+
+// Compile32 returns a Plan32 which activates the Network in float32. It has the
+// same topology as the Plan returned by Compile, with the weights, bias and
+// response rounded to the nearest float32.
+func (n *Network) Compile32() (*Plan32, error) {
+	p, err := n.Compile()
+	if err != nil {
+		return nil, err
+	}
+	return convertPlan[float32](p), nil
+}
+
+// Returns a copy of p which works with values of type U. The topology, which
+// never changes, is shared.
+func convertPlan[U, T Float](p *PlanOf[T]) *PlanOf[U] {
+	q := &PlanOf[U]{
+		nodeCount:    p.nodeCount,
+		inputOffset:  p.inputOffset,
+		inputCount:   p.inputCount,
+		outputOffset: p.outputOffset,
+		outputCount:  p.outputCount,
+		transform:    p.transform,
+		inShift:      convertSlice[U](p.inShift),
+		inScale:      convertSlice[U](p.inScale),
+		outShift:     convertSlice[U](p.outShift),
+		outScale:     convertSlice[U](p.outScale),
+		nodeTypes:    p.nodeTypes,
+		funcs:        p.funcs,
+		aggs:         p.aggs,
+		bias:         convertSlice[U](p.bias),
+		response:     convertSlice[U](p.response),
+		order:        p.order,
+		start:        p.start,
+		from:         p.from,
+		weights:      convertSlice[U](p.weights),
+		maxIn:        p.maxIn,
+	}
+	q.states.New = func() interface{} { return q.NewState() }
+	return q
+}
+
+// Returns a copy of x converted to values of type U
+func convertSlice[U, T Float](x []T) []U {
+	if x == nil {
+		return nil
+	}
+	y := make([]U, len(x))
+	for i, v := range x {
+		y[i] = U(v)
+	}
+	return y
+}
+
+// Returns ErrState unless s was made by the Plan's NewState
+func (p *PlanOf[T]) checkState(s *StateOf[T]) error {
+	if s == nil || s.plan != p {
+		return ErrState
+	}
+	return nil
+}
+
+// NewState returns a state for use with the Plan
+func (p *PlanOf[T]) NewState() *StateOf[T] {
+	return &StateOf[T]{
+		plan:   p,
+		acts:   make([]T, p.nodeCount),
+		values: make([]T, 0, p.maxIn),
+	}
+}
+
+// Activate activates the Plan with inputs and returns a new slice of outputs.
+// It panics with ErrInputSize if there are the wrong number of inputs; use
+// ActivateInto to have the error returned instead.
+func (p *PlanOf[T]) Activate(inputs []T) (outputs []T) {
+	outputs = make([]T, p.outputCount)
+	if err := p.ActivateInto(inputs, outputs); err != nil {
+		panic(err)
+	}
+	return
+}
+
+// ActivateInto activates the Plan with inputs and writes the outputs into the
+// given buffer without allocating, using a state from the Plan's pool
+func (p *PlanOf[T]) ActivateInto(inputs, outputs []T) error {
+	s := p.states.Get().(*StateOf[T])
+	err := p.ActivateState(s, inputs, outputs)
+	p.states.Put(s)
+	return err
+}
+
+// ActivateState activates the Plan with inputs using the caller's state, and
+// writes the outputs into the given buffer without allocating. It returns
+// ErrState if the state came from a different Plan.
+func (p *PlanOf[T]) ActivateState(s *StateOf[T], inputs, outputs []T) error {
+	if err := p.checkState(s); err != nil {
+		return err
+	}
+	if len(inputs) != p.inputCount {
+		return ErrInputSize
+	}
+	if len(outputs) != p.outputCount {
+		return ErrOutputSize
+	}
+
+	p.run(s, inputs)
+	copy(outputs, s.acts[p.outputOffset:p.outputOffset+p.outputCount])
+	p.finish(outputs)
+	return nil
+}
+
+// Activates every node of the Plan into s.acts
+func (p *PlanOf[T]) run(s *StateOf[T], inputs []T) {
+	acts := s.acts
+
+	// Activate the bias and input nodes
+	for i := 0; i < p.outputOffset; i++ {
+		value := T(1)
+		if p.nodeTypes[i] == INPUT {
+			value = p.scaleInput(i-p.inputOffset, inputs[i-p.inputOffset])
+		}
+		acts[i] = activation(p.funcs[i], p.bias[i]+p.response[i]*value)
+	}
+
+	// Activate the rest in order
+	for k, j := range p.order {
+		from := p.from[p.start[k]:p.start[k+1]]
+		weights := p.weights[p.start[k]:p.start[k+1]]
+
+		var agg T
+		if p.aggs[j] == SUM {
+			for c, i := range from {
+				agg += acts[i] * weights[c]
+			}
+		} else {
+			values := s.values[:0]
+			for c, i := range from {
+				agg, values = aggregateInto(p.aggs[j], agg, c, values, acts[i]*weights[c])
+			}
+		}
+		acts[j] = activation(p.funcs[j], p.bias[j]+p.response[j]*agg)
+	}
+}
+
+// ActivateBatch activates the Plan once for each row of inputs and returns one
+// row of outputs for each. The results match calling Activate for each row.
+func (p *PlanOf[T]) ActivateBatch(inputs [][]T) ([][]T, error) {
+	flat := make([]T, 0, len(inputs)*p.inputCount)
+	for _, row := range inputs {
+		if len(row) != p.inputCount {
+			return nil, ErrInputSize
+		}
+		flat = append(flat, row...)
+	}
+
+	out := make([]T, len(inputs)*p.outputCount)
+	if err := p.ActivateBatchInto(flat, len(inputs), out); err != nil {
+		return nil, err
+	}
+
+	outputs := make([][]T, len(inputs))
+	for r := range outputs {
+		outputs[r] = out[r*p.outputCount : (r+1)*p.outputCount : (r+1)*p.outputCount]
+	}
+	return outputs, nil
+}
+
+// ActivateBatchInto activates the Plan for rows rows of inputs, held one row after
+// another in a flat slice, and writes the outputs the same way into outputs.
+// Each connection is applied to every row before moving on to the next, which
+// keeps the work for a node together in memory. Once the pooled states have
+// grown to fit the batch the Plan does not allocate.
+func (p *PlanOf[T]) ActivateBatchInto(inputs []T, rows int, outputs []T) error {
+	s := p.states.Get().(*StateOf[T])
+	err := p.ActivateBatchState(s, inputs, rows, outputs)
+	p.states.Put(s)
+	return err
+}
+
+// ActivateBatchState is ActivateBatchInto using the caller's state. It returns
+// ErrState if the state came from a different Plan.
+func (p *PlanOf[T]) ActivateBatchState(s *StateOf[T], inputs []T, rows int, outputs []T) error {
+	if err := p.checkState(s); err != nil {
+		return err
+	}
+	if rows < 0 || len(inputs) != rows*p.inputCount {
+		return ErrInputSize
+	}
+	if len(outputs) != rows*p.outputCount {
+		return ErrOutputSize
+	}
+
+	if cap(s.batch) < p.nodeCount*rows {
+		s.batch = make([]T, p.nodeCount*rows)
+	}
+	acts := s.batch[:p.nodeCount*rows]
+
+	// Activate the bias and input nodes
+	for i := 0; i < p.outputOffset; i++ {
+		dst := acts[i*rows : (i+1)*rows]
+		for r := range dst {
+			value := T(1)
+			if p.nodeTypes[i] == INPUT {
+				value = p.scaleInput(i-p.inputOffset, inputs[r*p.inputCount+i-p.inputOffset])
+			}
+			dst[r] = activation(p.funcs[i], p.bias[i]+p.response[i]*value)
+		}
+	}
+
+	// Activate the rest in order
+	for k, j := range p.order {
+		from := p.from[p.start[k]:p.start[k+1]]
+		weights := p.weights[p.start[k]:p.start[k+1]]
+		dst := acts[j*rows : (j+1)*rows]
+
+		if p.aggs[j] == SUM {
+			for r := range dst {
+				dst[r] = 0
+			}
+			for c, i := range from {
+				src, w := acts[i*rows:(i+1)*rows], weights[c]
+				for r := range dst {
+					dst[r] += src[r] * w
+				}
+			}
+		} else {
+			for r := range dst {
+				agg, values := T(0), s.values[:0]
+				for c, i := range from {
+					agg, values = aggregateInto(p.aggs[j], agg, c, values, acts[i*rows+r]*weights[c])
+				}
+				dst[r] = agg
+			}
+		}
+
+		for r := range dst {
+			dst[r] = activation(p.funcs[j], p.bias[j]+p.response[j]*dst[r])
+		}
+	}
+
+	// Return the outputs
+	for o := 0; o < p.outputCount; o++ {
+		src := acts[(p.outputOffset+o)*rows : (p.outputOffset+o+1)*rows]
+		for r, x := range src {
+			outputs[r*p.outputCount+o] = x
+		}
+	}
+	for r := 0; r < rows; r++ {
+		p.finish(outputs[r*p.outputCount : (r+1)*p.outputCount])
+	}
+	return nil
+}
+
+// Returns input i after the input scaler, if any
+func (p *PlanOf[T]) scaleInput(i int, x T) T {
+	if p.inShift == nil {
+		return x
+	}
+	return (x - p.inShift[i]) / p.inScale[i]
+}
+
+// Applies the output transform and then the inverse of the output scaler, if
+// any, to one row of outputs
+func (p *PlanOf[T]) finish(outputs []T) {
+	transformOutputs(p.transform, outputs)
+	if p.outShift != nil {
+		for i, x := range outputs {
+			outputs[i] = x*p.outScale[i] + p.outShift[i]
+		}
+	}
+}
+
+// Returns the activation function of funcType applied to x
+func activation[T Float](funcType FuncType, x T) T {
+	switch funcType {
+	case SIGMOID:
+		return T(1.0 / (1.0 + math.Exp(-float64(x))))
+	case STEEPENED_SIGMOID:
+		return T(1.0 / (1.0 + math.Exp(-4.9*float64(x))))
+	case RELU:
+		if x > 0 {
+			return x
+		}
+		return 0
+	case TANH:
+		return T(math.Tanh(float64(x)))
+	}
+	return x
+}
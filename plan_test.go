@@ -0,0 +1,306 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"sync"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	Convey("Subject: Plan", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+		net, _ := NewLayered(4, []LayerSpec{{Size: 6, Func: RELU}, {Size: 5, Func: TANH, Agg: MEDIAN}, {Size: 3, Func: SIGMOID, Agg: MAX}})
+		net.nodes[len(net.nodes)-1].SetBias(0.3)
+		net.nodes[len(net.nodes)-1].SetResponse(1.5)
+		inputs := []float64{0.1, -0.7, 0.4, 0.9}
+
+		Convey("Given a compiled Network", func() {
+			plan, err := net.Compile()
+			So(err, ShouldBeNil)
+
+			Convey("Activation should match the Network", func() {
+				So(plan.Activate(inputs), ShouldResemble, net.Activate(inputs))
+			})
+			Convey("Later changes to the Network should not be seen", func() {
+				before := plan.Activate(inputs)
+				net.conns[0].SetWeight(net.conns[0].Weight() + 1)
+				So(plan.Activate(inputs), ShouldResemble, before)
+			})
+			Convey("Wrongly sized buffers should be rejected", func() {
+				So(plan.ActivateInto(inputs[:3], make([]float64, 3)), ShouldEqual, ErrInputSize)
+				So(plan.ActivateInto(inputs, make([]float64, 2)), ShouldEqual, ErrOutputSize)
+			})
+			Convey("ActivateInto should not allocate", func() {
+				outputs := make([]float64, 3)
+				allocs := testing.AllocsPerRun(100, func() {
+					plan.ActivateInto(inputs, outputs)
+				})
+				if !raceEnabled {
+					So(allocs, ShouldEqual, 0)
+				}
+			})
+		})
+
+		Convey("Given a batch of inputs", func() {
+			plan, _ := net.Compile()
+			batch := make([][]float64, 50)
+			for r := range batch {
+				batch[r] = []float64{random.Next(), random.Next() - 1, random.Next() * 2, -random.Next()}
+			}
+
+			Convey("Each row should match Activate", func() {
+				outputs, err := plan.ActivateBatch(batch)
+				So(err, ShouldBeNil)
+				So(len(outputs), ShouldEqual, len(batch))
+				for r, row := range batch {
+					So(outputs[r], ShouldResemble, net.Activate(row))
+				}
+			})
+			Convey("Wrongly sized rows should be rejected", func() {
+				batch[3] = batch[3][:2]
+				_, err := plan.ActivateBatch(batch)
+				So(err, ShouldEqual, ErrInputSize)
+				So(plan.ActivateBatchInto(make([]float64, 8), 2, make([]float64, 5)), ShouldEqual, ErrOutputSize)
+			})
+			Convey("ActivateBatchInto should not allocate once warmed up", func() {
+				flat := make([]float64, 0, len(batch)*4)
+				for _, row := range batch {
+					flat = append(flat, row...)
+				}
+				outputs := make([]float64, len(batch)*3)
+				allocs := testing.AllocsPerRun(20, func() {
+					plan.ActivateBatchInto(flat, len(batch), outputs)
+				})
+				if !raceEnabled {
+					So(allocs, ShouldEqual, 0)
+				}
+			})
+		})
+
+		Convey("Given many goroutines sharing a Plan", func() {
+			plan, _ := net.Compile()
+			rows := make([][]float64, 16)
+			want := make([][]float64, len(rows))
+			for r := range rows {
+				rows[r] = []float64{random.Next(), random.Next(), random.Next(), random.Next()}
+				want[r] = net.Activate(rows[r])
+			}
+
+			got := make([][]float64, len(rows))
+			var wg sync.WaitGroup
+			for r := range rows {
+				wg.Add(1)
+				go func(r int) {
+					defer wg.Done()
+					state := plan.NewState()
+					outputs := make([]float64, 3)
+					for i := 0; i < 200; i++ {
+						if i%2 == 0 {
+							plan.ActivateInto(rows[r], outputs)
+						} else {
+							plan.ActivateState(state, rows[r], outputs)
+						}
+					}
+					got[r] = outputs
+				}(r)
+			}
+			wg.Wait()
+
+			for r := range rows {
+				So(got[r], ShouldResemble, want[r])
+			}
+		})
+
+		Convey("Given a caller owned ActivationState", func() {
+			plan, _ := net.Compile()
+			state := plan.NewState()
+			outputs := make([]float64, 3)
+			So(plan.ActivateState(state, inputs, outputs), ShouldBeNil)
+			So(outputs, ShouldResemble, net.Activate(inputs))
+			allocs := testing.AllocsPerRun(100, func() {
+				plan.ActivateState(state, inputs, outputs)
+			})
+			So(allocs, ShouldEqual, 0)
+		})
+		Convey("Activate should panic on the wrong number of inputs", func() {
+			plan, _ := net.Compile()
+			So(func() { plan.Activate(inputs[:1]) }, ShouldPanic)
+		})
+		Convey("A state from a different Plan should be rejected", func() {
+			plan, _ := net.Compile()
+			other, _ := NewLayered(4, []LayerSpec{{Size: 3, Func: SIGMOID}})
+			otherPlan, _ := other.Compile()
+			outputs := make([]float64, 3)
+			So(plan.ActivateState(otherPlan.NewState(), inputs, outputs), ShouldEqual, ErrState)
+			So(plan.ActivateState(nil, inputs, outputs), ShouldEqual, ErrState)
+			So(plan.ActivateBatchState(otherPlan.NewState(), inputs, 1, outputs), ShouldEqual, ErrState)
+			samePlan, _ := net.Compile()
+			So(plan.ActivateState(samePlan.NewState(), inputs, outputs), ShouldEqual, ErrState)
+		})
+
+		Convey("Given a float32 Plan", func() {
+			plan32, err := net.Compile32()
+			So(err, ShouldBeNil)
+			want := net.Activate(inputs)
+			inputs32 := []float32{0.1, -0.7, 0.4, 0.9}
+
+			Convey("Activation should be within float32 tolerance of float64", func() {
+				for i, x := range plan32.Activate(inputs32) {
+					So(float64(x), ShouldAlmostEqual, want[i], 1e-5)
+				}
+			})
+			Convey("Batch activation should be within float32 tolerance of float64", func() {
+				outputs, err := plan32.ActivateBatch([][]float32{inputs32, inputs32})
+				So(err, ShouldBeNil)
+				for _, row := range outputs {
+					for i, x := range row {
+						So(float64(x), ShouldAlmostEqual, want[i], 1e-5)
+					}
+				}
+			})
+			Convey("ActivateInto should not allocate", func() {
+				outputs := make([]float32, 3)
+				allocs := testing.AllocsPerRun(100, func() {
+					plan32.ActivateInto(inputs32, outputs)
+				})
+				if !raceEnabled {
+					So(allocs, ShouldEqual, 0)
+				}
+			})
+		})
+
+		Convey("Given connections out of activation order", func() {
+			g := NewGraph().Bias("b").Input("in").Hidden("h1", SIGMOID).Hidden("h2", TANH).Output("out", SIGMOID).
+				Connect("h2", "out", 0.7).Connect("h1", "h2", -1.1).Connect("in", "h1", 0.5).Connect("b", "h1", 0.2).Connect("in", "out", 0.3)
+			sorted, _ := g.Build()
+			unsorted := &Network{}
+			for _, x := range sorted.nodes {
+				unsorted.AddNode(x)
+			}
+			for i := len(sorted.conns) - 1; i >= 0; i-- {
+				unsorted.AddConnection(sorted.conns[i])
+			}
+			plan, err := unsorted.Compile()
+			So(err, ShouldBeNil)
+			So(plan.Activate([]float64{0.6}), ShouldResemble, sorted.Activate([]float64{0.6}))
+		})
+
+		Convey("Given connections which form a cycle", func() {
+			h := NewNode(SIGMOID, HIDDEN)
+			cyclic := &Network{}
+			cyclic.AddNode(h)
+			cyclic.AddConnection(NewConnection(h, h, 1))
+			_, err := cyclic.Compile()
+			So(err, ShouldEqual, ErrCycle)
+		})
+	})
+}
+
+// Network used by the activation benchmarks
+func benchNetwork() (*Network, []float64) {
+	random.Reseed(0)
+	net, _ := NewLayered(32, []LayerSpec{{Size: 64, Func: SIGMOID}, {Size: 32, Func: SIGMOID}, {Size: 8, Func: SIGMOID}})
+	inputs := make([]float64, 32)
+	for i := range inputs {
+		inputs[i] = random.Next()
+	}
+	return net, inputs
+}
+
+func BenchmarkNetworkActivate(b *testing.B) {
+	net, inputs := benchNetwork()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		net.Activate(inputs)
+	}
+}
+
+func BenchmarkPlanActivate(b *testing.B) {
+	net, inputs := benchNetwork()
+	plan, _ := net.Compile()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		plan.Activate(inputs)
+	}
+}
+
+func BenchmarkNetworkActivateInto(b *testing.B) {
+	net, inputs := benchNetwork()
+	outputs := make([]float64, 8)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		net.ActivateInto(inputs, outputs)
+	}
+}
+
+func BenchmarkPlanActivateInto(b *testing.B) {
+	net, inputs := benchNetwork()
+	plan, _ := net.Compile()
+	outputs := make([]float64, 8)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		plan.ActivateInto(inputs, outputs)
+	}
+}
+
+func BenchmarkPlanActivateBatchInto(b *testing.B) {
+	net, inputs := benchNetwork()
+	plan, _ := net.Compile()
+	const rows = 256
+	batch := make([]float64, 0, rows*len(inputs))
+	for r := 0; r < rows; r++ {
+		batch = append(batch, inputs...)
+	}
+	outputs := make([]float64, rows*8)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		plan.ActivateBatchInto(batch, rows, outputs)
+	}
+}
+
+func BenchmarkPlan32ActivateInto(b *testing.B) {
+	net, inputs := benchNetwork()
+	plan, _ := net.Compile32()
+	inputs32 := make([]float32, len(inputs))
+	for i, x := range inputs {
+		inputs32[i] = float32(x)
+	}
+	outputs := make([]float32, 8)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		plan.ActivateInto(inputs32, outputs)
+	}
+}
@@ -0,0 +1,158 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"fmt"
+	"math"
+)
+
+// PlasticityRule identifies how a PlasticConnection changes its weight
+type PlasticityRule byte
+
+// Constants for PlasticityRules. pre and post are the activations of the From
+// and To nodes and w the current weight.
+const (
+	HEBB PlasticityRule = iota // Δw = Eta * pre * post
+	OJA                        // Δw = Eta * post * (pre - post * w)
+	ABCD                       // Δw = Eta * (A*pre*post + B*pre + C*post + D)
+)
+
+var (
+	PlasticityRules = []PlasticityRule{HEBB, OJA, ABCD}
+)
+
+// Plasticity holds a PlasticityRule and its coefficients, which evolution can
+// tune per connection
+type Plasticity struct {
+	Rule PlasticityRule `json:"rule"`
+	Eta  float64        `json:"eta"` // Learning rate of the rule
+	A    float64        `json:"a,omitempty"`
+	B    float64        `json:"b,omitempty"`
+	C    float64        `json:"c,omitempty"`
+	D    float64        `json:"d,omitempty"`
+
+	// Limit, if positive, keeps the weight between -Limit and Limit
+	Limit float64 `json:"limit,omitempty"`
+}
+
+// Returns the change in weight for the activations either side of a connection
+func (p Plasticity) delta(pre, post, w float64) float64 {
+	switch p.Rule {
+	case OJA:
+		return p.Eta * post * (pre - post*w)
+	case ABCD:
+		return p.Eta * (p.A*pre*post + p.B*pre + p.C*post + p.D)
+	}
+	return p.Eta * pre * post
+}
+
+// PlasticConnection is a Connection whose weight changes each time the Network
+// is activated, following a local Hebbian rule. The weight it starts from is
+// kept, so the learned changes can be undone with ResetPlasticity. Only Activate
+// and ActivateInto change the weight; training, Evaluate and compiled Plans use
+// it as it stands. SetWeight, as used by training, changes only the current
+// weight; SetInitialWeight changes the weight it resets to.
+type PlasticConnection struct {
+	connection
+	plasticity Plasticity
+	initial    float64 // Weight before any plastic change
+}
+
+// Implemented by Connections whose weight changes as the Network runs
+type adaptiveConnection interface {
+	Connection
+	adapt()
+	ResetPlasticity()
+}
+
+// NewPlasticConnection returns a pointer to a new PlasticConnection
+func NewPlasticConnection(fromNode Node, toNode Node, weight float64, plasticity Plasticity) *PlasticConnection {
+	return &PlasticConnection{connection: connection{fromNode, toNode, weight}, plasticity: plasticity, initial: weight}
+}
+
+// InitialWeight returns the weight the connection resets to
+func (c *PlasticConnection) InitialWeight() float64 {
+	return c.initial
+}
+
+// SetInitialWeight replaces the weight the connection resets to, leaving the
+// current weight unchanged
+func (c *PlasticConnection) SetInitialWeight(weight float64) {
+	c.initial = weight
+}
+
+// Plasticity returns the rule and coefficients of the connection
+func (c *PlasticConnection) Plasticity() Plasticity {
+	return c.plasticity
+}
+
+// SetPlasticity replaces the rule and coefficients of the connection
+func (c *PlasticConnection) SetPlasticity(plasticity Plasticity) {
+	c.plasticity = plasticity
+}
+
+// ResetPlasticity undoes every change the rule has made to the weight
+func (c *PlasticConnection) ResetPlasticity() {
+	c.weight = c.initial
+}
+
+// adapt changes the weight by the rule, given the final activations of both
+// nodes and any modulation of the target
+func (c *PlasticConnection) adapt() {
+	w := c.weight + plasticityScale(c.toNode)*c.plasticity.delta(c.fromNode.Activate(), c.toNode.Activate(), c.weight)
+	if limit := c.plasticity.Limit; limit > 0 {
+		w = math.Max(-limit, math.Min(limit, w))
+	}
+	c.weight = w
+}
+
+func (c *PlasticConnection) String() string {
+	return fmt.Sprintf("%v (from %v), %v, %v, plastic %d", c.weight, c.initial, c.fromNode.NodeType(), c.toNode.NodeType(), c.plasticity.Rule)
+}
+
+// Changes the weights of the plastic connections after an activation
+func (n *Network) adapt() {
+	if n.plastic == 0 {
+		return
+	}
+	for _, c := range n.conns {
+		if a, ok := c.(adaptiveConnection); ok {
+			a.adapt()
+		}
+	}
+}
+
+// ResetPlasticity undoes the learned changes to the weights of every
+// PlasticConnection in the Network
+func (n *Network) ResetPlasticity() {
+	for _, c := range n.conns {
+		if a, ok := c.(adaptiveConnection); ok {
+			a.ResetPlasticity()
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestPlasticConnection(t *testing.T) {
+	Convey("Subject: Plastic connections", t, func() {
+		Convey("Each rule should give the expected change", func() {
+			So(Plasticity{Rule: HEBB, Eta: 0.5}.delta(2, 3, 1), ShouldEqual, 3.0)
+			So(Plasticity{Rule: OJA, Eta: 0.5}.delta(2, 3, 1), ShouldEqual, 0.5*3*(2-3))
+			So(Plasticity{Rule: ABCD, Eta: 0.5, A: 1, B: 2, C: 3, D: 4}.delta(2, 3, 1), ShouldEqual, 0.5*(6+4+9+4))
+		})
+
+		// A single input feeding a single DIRECT output, so post = pre * w
+		newNet := func(p Plasticity) (*Network, *PlasticConnection) {
+			in, out := NewNode(DIRECT, INPUT), NewNode(DIRECT, OUTPUT)
+			c := NewPlasticConnection(in, out, 0.5, p)
+			net := &Network{}
+			net.AddNode(in)
+			net.AddNode(out)
+			net.AddConnection(c)
+			So(net.plastic, ShouldEqual, 1)
+			return net, c
+		}
+
+		Convey("Activate should change the weight after each run", func() {
+			net, c := newNet(Plasticity{Rule: HEBB, Eta: 0.1})
+			So(net.Activate([]float64{2})[0], ShouldEqual, 1.0)
+			So(c.Weight(), ShouldAlmostEqual, 0.5+0.1*2*1)
+			So(net.Activate([]float64{2})[0], ShouldAlmostEqual, 1.4)
+		})
+		Convey("The weight should stay within the limit", func() {
+			net, c := newNet(Plasticity{Rule: HEBB, Eta: 1, Limit: 2})
+			for i := 0; i < 10; i++ {
+				net.Activate([]float64{1})
+			}
+			So(c.Weight(), ShouldEqual, 2.0)
+		})
+		Convey("Oja's rule should keep the weight bounded", func() {
+			net, c := newNet(Plasticity{Rule: OJA, Eta: 0.1})
+			for i := 0; i < 1000; i++ {
+				net.Activate([]float64{1})
+			}
+			So(c.Weight(), ShouldAlmostEqual, 1.0, 1e-6)
+		})
+		Convey("Resetting should restore the starting weight", func() {
+			net, c := newNet(Plasticity{Rule: HEBB, Eta: 0.1})
+			net.Activate([]float64{2})
+			net.ResetPlasticity()
+			So(c.Weight(), ShouldEqual, 0.5)
+			c.SetInitialWeight(0.7)
+			So(c.Weight(), ShouldEqual, 0.5)
+			net.Activate([]float64{2})
+			net.ResetPlasticity()
+			So(c.Weight(), ShouldEqual, 0.7)
+		})
+		Convey("Training should change only the current weight", func() {
+			net, c := newNet(Plasticity{Rule: HEBB, Eta: 0.1})
+			trainer := &Trainer{LearningRate: 0.1, Epochs: 5}
+			So(trainer.Train(net, Dataset{Inputs: [][]float64{{2}}, Targets: [][]float64{{3}}}), ShouldBeNil)
+			So(c.Weight(), ShouldNotEqual, 0.5)
+			So(c.InitialWeight(), ShouldEqual, 0.5)
+		})
+		Convey("Evaluating should not change the weight", func() {
+			net, c := newNet(Plasticity{Rule: HEBB, Eta: 0.1})
+			net.Evaluate(Dataset{Inputs: [][]float64{{2}}, Targets: [][]float64{{1}}}, SQUARED_ERROR)
+			So(c.Weight(), ShouldEqual, 0.5)
+		})
+		Convey("The rule and state should survive serialization", func() {
+			net, _ := newNet(Plasticity{Rule: ABCD, Eta: 0.1, A: 1, B: -0.5, D: 0.2, Limit: 4})
+			net.Activate([]float64{2})
+			data, err := json.Marshal(net)
+			So(err, ShouldBeNil)
+
+			var restored Network
+			So(json.Unmarshal(data, &restored), ShouldBeNil)
+			c, ok := restored.conns[0].(*PlasticConnection)
+			So(ok, ShouldBeTrue)
+			So(c.Plasticity(), ShouldResemble, net.conns[0].(*PlasticConnection).Plasticity())
+			So(c.Weight(), ShouldEqual, net.conns[0].Weight())
+			So(c.InitialWeight(), ShouldEqual, 0.5)
+			So(restored.Activate([]float64{1}), ShouldResemble, net.Activate([]float64{1}))
+		})
+	})
+}
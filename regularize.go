@@ -0,0 +1,109 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"math"
+)
+
+// Regularization penalizes large connection weights to reduce overfitting
+type Regularization struct {
+	L1 float64 // Adds L1 times the sum of the absolute weights to the loss
+	L2 float64 // Adds L2/2 times the sum of the squared weights to the loss
+
+	// MaxNorm, if positive, limits the Euclidean norm of the weights into each
+	// node. Weights over the limit are scaled down after each step.
+	MaxNorm float64
+
+	// ExcludeBias leaves connections from BIAS nodes out of the penalties and
+	// the max-norm constraint
+	ExcludeBias bool
+}
+
+// Returns true if the connection's weight is regularized
+func (r Regularization) covers(c Connection) bool {
+	return !r.ExcludeBias || c.From().NodeType() != BIAS
+}
+
+// Penalty returns the amount the Regularization adds to the loss of the Network
+func (r Regularization) Penalty(n *Network) float64 {
+	if r.L1 == 0 && r.L2 == 0 {
+		return 0
+	}
+	penalty := 0.0
+	for _, c := range n.conns {
+		if r.covers(c) {
+			w := c.Weight()
+			penalty += r.L1*math.Abs(w) + r.L2*w*w/2
+		}
+	}
+	return penalty
+}
+
+// AddGradients adds scale times the gradient of the penalty to the weight
+// gradients. At a weight of exactly 0 the L1 gradient is taken as 0.
+func (r Regularization) AddGradients(n *Network, grads *Gradients, scale float64) {
+	if r.L1 == 0 && r.L2 == 0 {
+		return
+	}
+	for i, c := range n.conns {
+		if r.covers(c) {
+			w := c.Weight()
+			grads.Weights[i] += scale * (r.L1*sign(w) + r.L2*w)
+		}
+	}
+}
+
+// Constrain scales down the weights into any node whose incoming weights have a
+// norm over MaxNorm
+func (r Regularization) Constrain(n *Network) {
+	if r.MaxNorm <= 0 {
+		return
+	}
+	norms := make(map[Node]float64, len(n.nodes))
+	for _, c := range n.conns {
+		if r.covers(c) {
+			norms[c.To()] += c.Weight() * c.Weight()
+		}
+	}
+	for _, c := range n.conns {
+		if norm := math.Sqrt(norms[c.To()]); norm > r.MaxNorm && r.covers(c) {
+			c.SetWeight(c.Weight() * r.MaxNorm / norm)
+		}
+	}
+}
+
+// Returns -1, 0 or 1 by the sign of x
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+// Returns the sum of the absolute connection weights of the Network
+func weightSum(n *Network) float64 {
+	sum := 0.0
+	for _, c := range n.conns {
+		sum += math.Abs(c.Weight())
+	}
+	return sum
+}
+
+func TestRegularization(t *testing.T) {
+	Convey("Subject: Regularization", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+		newNet := func() *Network {
+			n, _ := NewGraph().Bias("b").Input("x").Output("y", DIRECT).
+				Connect("b", "y", 3).Connect("x", "y", -4).Build()
+			return n
+		}
+		net := newNet()
+
+		Convey("The penalty should add the L1 and L2 terms", func() {
+			r := Regularization{L1: 0.1, L2: 0.2}
+			So(r.Penalty(net), ShouldAlmostEqual, 0.1*7+0.1*25)
+		})
+		Convey("Excluding the bias should leave out its connections", func() {
+			r := Regularization{L1: 0.1, L2: 0.2, ExcludeBias: true}
+			So(r.Penalty(net), ShouldAlmostEqual, 0.1*4+0.1*16)
+		})
+		Convey("The gradient should match the penalty", func() {
+			r := Regularization{L1: 0.1, L2: 0.2}
+			grads := net.NewGradients()
+			r.AddGradients(net, grads, 1)
+			for i, c := range net.conns {
+				w := c.Weight()
+				c.SetWeight(w + 1e-6)
+				plus := r.Penalty(net)
+				c.SetWeight(w - 1e-6)
+				minus := r.Penalty(net)
+				c.SetWeight(w)
+				So(grads.Weights[i], ShouldAlmostEqual, (plus-minus)/2e-6, 1e-6)
+			}
+		})
+		Convey("The max-norm constraint should scale down large weights", func() {
+			net := newNet()
+			Regularization{MaxNorm: 1}.Constrain(net)
+			So(net.conns[0].Weight(), ShouldAlmostEqual, 0.6)
+			So(net.conns[1].Weight(), ShouldAlmostEqual, -0.8)
+		})
+		Convey("The max-norm constraint should leave small weights alone", func() {
+			net := newNet()
+			Regularization{MaxNorm: 10}.Constrain(net)
+			So(net.conns[0].Weight(), ShouldEqual, 3)
+		})
+		Convey("The max-norm constraint should skip the bias when excluded", func() {
+			net := newNet()
+			Regularization{MaxNorm: 2, ExcludeBias: true}.Constrain(net)
+			So(net.conns[0].Weight(), ShouldEqual, 3)
+			So(net.conns[1].Weight(), ShouldAlmostEqual, -2)
+		})
+		Convey("Training with a penalty should give smaller weights", func() {
+			build := func() *Network {
+				random.Reseed(0)
+				n, _ := NewLayered(2, []LayerSpec{{Size: 4, Func: TANH}, {Size: 1, Func: SIGMOID}})
+				return n
+			}
+			plain, penalized := build(), build()
+			trainer := &Trainer{LearningRate: 2, Epochs: 500, BatchSize: 2, Seed: 1}
+			So(trainer.Train(plain, xorDataset()), ShouldBeNil)
+			trainer.Regularization = Regularization{L2: 0.01}
+			So(trainer.Train(penalized, xorDataset()), ShouldBeNil)
+			So(weightSum(penalized), ShouldBeLessThan, weightSum(plain))
+
+			loss, _ := penalized.Evaluate(xorDataset(), SQUARED_ERROR)
+			objective, err := trainer.Objective(penalized, xorDataset())
+			So(err, ShouldBeNil)
+			So(objective, ShouldAlmostEqual, loss+trainer.Regularization.Penalty(penalized))
+		})
+	})
+}
@@ -0,0 +1,232 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ScalerType identifies how a Scaler was fitted
+type ScalerType byte
+
+// Constants for ScalerTypes
+const (
+	STANDARD ScalerType = iota // Zero mean and unit variance
+	MIN_MAX                    // Between 0 and 1
+)
+
+// Scaler maps each column of values to a standard range: scaled = (x - Shift) / Scale.
+// Attached to a Network, it lets Activate take and return values in their
+// original units.
+type Scaler struct {
+	Type  ScalerType `json:"type"`
+	Shift []float64  `json:"shift"`
+	Scale []float64  `json:"scale"`
+}
+
+// FitStandard returns a Scaler which gives each column of rows a mean of 0 and
+// a standard deviation of 1
+func FitStandard(rows [][]float64) (*Scaler, error) {
+	s, err := newScaler(STANDARD, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		for i, x := range row {
+			s.Shift[i] += x / float64(len(rows))
+		}
+	}
+	for _, row := range rows {
+		for i, x := range row {
+			s.Scale[i] += (x - s.Shift[i]) * (x - s.Shift[i]) / float64(len(rows))
+		}
+	}
+	for i, v := range s.Scale {
+		s.Scale[i] = math.Sqrt(v)
+	}
+
+	s.fixConstant()
+	return s, nil
+}
+
+// FitMinMax returns a Scaler which maps each column of rows onto [0,1]
+func FitMinMax(rows [][]float64) (*Scaler, error) {
+	s, err := newScaler(MIN_MAX, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	copy(s.Shift, rows[0])
+	max := append([]float64(nil), rows[0]...)
+	for _, row := range rows[1:] {
+		for i, x := range row {
+			s.Shift[i] = math.Min(s.Shift[i], x)
+			max[i] = math.Max(max[i], x)
+		}
+	}
+	for i := range s.Scale {
+		s.Scale[i] = max[i] - s.Shift[i]
+	}
+
+	s.fixConstant()
+	return s, nil
+}
+
+// Returns an empty Scaler sized for rows, checking they all have the same length
+func newScaler(scalerType ScalerType, rows [][]float64) (*Scaler, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("neural: cannot fit a scaler to no rows")
+	}
+	for _, row := range rows {
+		if len(row) != len(rows[0]) {
+			return nil, errors.New("neural: cannot fit a scaler to rows of different lengths")
+		}
+	}
+	return &Scaler{
+		Type:  scalerType,
+		Shift: make([]float64, len(rows[0])),
+		Scale: make([]float64, len(rows[0])),
+	}, nil
+}
+
+// Leaves constant columns unscaled rather than dividing by 0
+func (s *Scaler) fixConstant() {
+	for i, v := range s.Scale {
+		if v == 0 {
+			s.Scale[i] = 1
+		}
+	}
+}
+
+// Checks the Scaler has a shift and a nonzero scale for every column
+func (s *Scaler) check() error {
+	if len(s.Shift) != len(s.Scale) {
+		return fmt.Errorf("neural: scaler has %d shifts but %d scales", len(s.Shift), len(s.Scale))
+	}
+	for i, v := range s.Scale {
+		if v == 0 {
+			return fmt.Errorf("neural: scaler column %d has a scale of 0", i)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of columns the Scaler maps
+func (s *Scaler) Len() int {
+	return len(s.Shift)
+}
+
+// Transform returns x, the value of column i, in scaled units
+func (s *Scaler) Transform(i int, x float64) float64 {
+	return (x - s.Shift[i]) / s.Scale[i]
+}
+
+// Inverse returns x, the scaled value of column i, in its original units
+func (s *Scaler) Inverse(i int, x float64) float64 {
+	return x*s.Scale[i] + s.Shift[i]
+}
+
+// SetInputScaler attaches a Scaler which is applied to the inputs of the Network
+// before activation. Pass nil to remove it. The Scaler must have as many
+// shifts as scales, none of them 0.
+func (n *Network) SetInputScaler(s *Scaler) error {
+	if s == nil {
+		n.inputScaler = nil
+		return nil
+	}
+	if err := s.check(); err != nil {
+		return err
+	}
+	if s.Len() != n.inputCount {
+		return ErrInputSize
+	}
+	n.inputScaler = s
+	return nil
+}
+
+// SetOutputScaler attaches a Scaler whose inverse is applied to the outputs of
+// the Network, so they are returned in their original units. Training scales the
+// targets with it instead. Pass nil to remove it. The Scaler must have as many
+// shifts as scales, none of them 0.
+func (n *Network) SetOutputScaler(s *Scaler) error {
+	if s == nil {
+		n.outputScaler = nil
+		return nil
+	}
+	if err := s.check(); err != nil {
+		return err
+	}
+	if s.Len() != n.outputCount {
+		return ErrOutputSize
+	}
+	n.outputScaler = s
+	return nil
+}
+
+// InputScaler returns the Scaler applied to the inputs of the Network, or nil
+func (n *Network) InputScaler() *Scaler {
+	return n.inputScaler
+}
+
+// OutputScaler returns the Scaler applied to the outputs of the Network, or nil
+func (n *Network) OutputScaler() *Scaler {
+	return n.outputScaler
+}
+
+// Returns the value of input i after the input scaler, if any
+func (n *Network) scaleInput(i int, x float64) float64 {
+	if n.inputScaler == nil {
+		return x
+	}
+	return n.inputScaler.Transform(i, x)
+}
+
+// Returns the targets in the units of the output nodes, allocating only if
+// there is an output scaler
+func (n *Network) scaleTargets(targets []float64) []float64 {
+	if n.outputScaler == nil {
+		return targets
+	}
+	scaled := make([]float64, len(targets))
+	for i, x := range targets {
+		scaled[i] = n.outputScaler.Transform(i, x)
+	}
+	return scaled
+}
+
+// Converts outputs back to their original units
+func (n *Network) unscaleOutputs(outputs []float64) {
+	if n.outputScaler == nil {
+		return
+	}
+	for i, x := range outputs {
+		outputs[i] = n.outputScaler.Inverse(i, x)
+	}
+}
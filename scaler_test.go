@@ -0,0 +1,130 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"encoding/json"
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+func TestScaler(t *testing.T) {
+	Convey("Subject: Scaler", t, func() {
+		rows := [][]float64{{1, 10, 5}, {2, 20, 5}, {3, 60, 5}}
+
+		Convey("FitStandard should give zero mean and unit variance", func() {
+			s, err := FitStandard(rows)
+			So(err, ShouldBeNil)
+			So(s.Shift, ShouldResemble, []float64{2, 30, 5})
+			So(s.Scale[0], ShouldAlmostEqual, math.Sqrt(2.0/3.0))
+			So(s.Scale[2], ShouldEqual, 1.0) // Constant column
+			So(s.Transform(0, 3), ShouldAlmostEqual, 1/math.Sqrt(2.0/3.0))
+		})
+		Convey("FitMinMax should map onto [0,1]", func() {
+			s, err := FitMinMax(rows)
+			So(err, ShouldBeNil)
+			So(s.Transform(1, 10), ShouldEqual, 0.0)
+			So(s.Transform(1, 60), ShouldEqual, 1.0)
+			So(s.Transform(1, 20), ShouldEqual, 0.2)
+			So(s.Inverse(1, 0.2), ShouldEqual, 20.0)
+		})
+		Convey("Fitting bad rows should fail", func() {
+			_, err := FitStandard(nil)
+			So(err, ShouldNotBeNil)
+			_, err = FitMinMax([][]float64{{1, 2}, {3}})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Given a Network with scalers", func() {
+			random.Reseed(0) // Get a predictable random number generation
+			net, _ := NewLayered(2, []LayerSpec{{Size: 4, Func: TANH}, {Size: 1, Func: DIRECT}})
+			in := &Scaler{Type: STANDARD, Shift: []float64{100, -5}, Scale: []float64{20, 0.5}}
+			out := &Scaler{Type: MIN_MAX, Shift: []float64{1000}, Scale: []float64{50}}
+			raw := []float64{130, -4}
+			plain := net.Activate([]float64{1.5, 2})
+			So(net.SetInputScaler(in), ShouldBeNil)
+			So(net.SetOutputScaler(out), ShouldBeNil)
+
+			Convey("Activate should take and return original units", func() {
+				So(net.Activate(raw)[0], ShouldAlmostEqual, plain[0]*50+1000)
+			})
+			Convey("Plans should apply the scalers", func() {
+				plan, _ := net.Compile()
+				So(plan.Activate(raw), ShouldResemble, net.Activate(raw))
+				plan32, _ := net.Compile32()
+				So(float64(plan32.Activate([]float32{130, -4})[0]), ShouldAlmostEqual, net.Activate(raw)[0], 1e-3)
+			})
+			Convey("Scalers of the wrong size should be rejected", func() {
+				So(net.SetInputScaler(out), ShouldEqual, ErrInputSize)
+				So(net.SetOutputScaler(in), ShouldEqual, ErrOutputSize)
+			})
+			Convey("Scalers with mismatched or zero scales should be rejected", func() {
+				So(net.SetInputScaler(&Scaler{Shift: []float64{0, 0}, Scale: []float64{1}}), ShouldNotBeNil)
+				So(net.SetOutputScaler(&Scaler{Shift: []float64{0}, Scale: []float64{0}}), ShouldNotBeNil)
+				So(net.InputScaler(), ShouldEqual, in)
+				So(net.OutputScaler(), ShouldEqual, out)
+			})
+			Convey("Scalers should be serialized", func() {
+				data, _ := json.Marshal(net)
+				var copy Network
+				So(json.Unmarshal(data, &copy), ShouldBeNil)
+				So(copy.InputScaler(), ShouldResemble, in)
+				So(copy.OutputScaler(), ShouldResemble, out)
+				So(copy.Activate(raw), ShouldResemble, net.Activate(raw))
+			})
+		})
+
+		Convey("Given raw data far outside the sigmoid's range", func() {
+			random.Reseed(0)
+			var ds Dataset
+			for i := 0; i < 20; i++ {
+				x := -500 + 50*float64(i)
+				ds.Add([]float64{x}, []float64{2000 + 3*x})
+			}
+			net, _ := NewLayered(1, []LayerSpec{{Size: 3, Func: SIGMOID}, {Size: 1, Func: DIRECT}})
+			in, _ := FitStandard(ds.Inputs)
+			out, _ := FitStandard(ds.Targets)
+			net.SetInputScaler(in)
+			net.SetOutputScaler(out)
+
+			trainer := &Trainer{LearningRate: 0.5, Epochs: 2000}
+			So(trainer.Train(net, ds), ShouldBeNil)
+			So(net.Activate([]float64{100})[0], ShouldAlmostEqual, 2300.0, 50.0)
+
+			// Objective should measure the loss in the scaled units training uses
+			total := 0.0
+			for i := range ds.Inputs {
+				total += net.BackpropLoss(trainer.Loss, ds.Inputs[i], ds.Targets[i], net.NewGradients())
+			}
+			objective, err := trainer.Objective(net, ds)
+			So(err, ShouldBeNil)
+			So(objective, ShouldAlmostEqual, total/float64(ds.Len()))
+		})
+	})
+}
@@ -0,0 +1,153 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"math"
+)
+
+// Schedule varies the learning rate over training
+type Schedule interface {
+
+	// Rate returns the learning rate for a step, given the Trainer's
+	// LearningRate as base. epoch counts the passes through the Dataset and step
+	// the steps taken since training began, both from 0.
+	Rate(base float64, epoch, step int) float64
+}
+
+// PlateauObserver is a Schedule which also watches the loss at the end of each
+// epoch
+type PlateauObserver interface {
+	Schedule
+	Observe(loss float64)
+}
+
+// StepDecay multiplies the rate by Factor every Every epochs
+type StepDecay struct {
+	Every  int
+	Factor float64
+}
+
+// Rate returns base * Factor^(epoch/Every)
+func (s StepDecay) Rate(base float64, epoch, step int) float64 {
+	if s.Every <= 0 {
+		return base
+	}
+	return base * math.Pow(s.Factor, float64(epoch/s.Every))
+}
+
+// ExponentialDecay multiplies the rate by Gamma every epoch
+type ExponentialDecay struct {
+	Gamma float64
+}
+
+// Rate returns base * Gamma^epoch
+func (s ExponentialDecay) Rate(base float64, epoch, step int) float64 {
+	return base * math.Pow(s.Gamma, float64(epoch))
+}
+
+// CosineRestarts anneals the rate from base down to Min*base along a half cosine
+// over Period epochs, then restarts at base. Each period is Mult times as long
+// as the one before; a Mult below 1 is taken as 1.
+type CosineRestarts struct {
+	Period int
+	Mult   float64
+	Min    float64
+}
+
+// Rate returns the annealed rate for the epoch
+func (s CosineRestarts) Rate(base float64, epoch, step int) float64 {
+	if s.Period <= 0 {
+		return base
+	}
+	mult := math.Max(s.Mult, 1)
+	period, t := float64(s.Period), float64(epoch)
+	for t >= period {
+		t -= period
+		period *= mult
+	}
+	low := s.Min * base
+	return low + (base-low)*(1+math.Cos(math.Pi*t/period))/2
+}
+
+// LinearWarmup raises the rate in a straight line from 0 over the first Steps
+// steps, then follows Then, or stays at base if Then is nil
+type LinearWarmup struct {
+	Steps int
+	Then  Schedule
+}
+
+// Rate returns the warmed up rate for the step
+func (s LinearWarmup) Rate(base float64, epoch, step int) float64 {
+	if s.Then != nil {
+		base = s.Then.Rate(base, epoch, step)
+	}
+	if step < s.Steps {
+		return base * float64(step+1) / float64(s.Steps+1)
+	}
+	return base
+}
+
+// ReduceOnPlateau multiplies the rate by Factor whenever the loss has not
+// improved by more than MinDelta for Patience epochs in a row, but not below
+// Min. The Trainer passes it the validation loss if there is a validation set,
+// otherwise the training loss. Use a new ReduceOnPlateau for each training run.
+type ReduceOnPlateau struct {
+	Factor   float64
+	Patience int
+	MinDelta float64
+	Min      float64
+
+	scale float64 // Product of the reductions so far
+	best  float64 // Lowest loss so far
+	wait  int     // Epochs since the loss last improved
+	seen  bool    // Whether any loss has been observed
+}
+
+// Rate returns the base rate after the reductions so far
+func (s *ReduceOnPlateau) Rate(base float64, epoch, step int) float64 {
+	if !s.seen {
+		return base
+	}
+	return math.Max(base*s.scale, s.Min)
+}
+
+// Observe records the loss at the end of an epoch
+func (s *ReduceOnPlateau) Observe(loss float64) {
+	if !s.seen {
+		s.seen, s.scale, s.best = true, 1, loss
+		return
+	}
+	if loss < s.best-s.MinDelta {
+		s.best, s.wait = loss, 0
+		return
+	}
+	if s.wait++; s.wait >= s.Patience {
+		s.scale *= s.Factor
+		s.wait = 0
+	}
+}
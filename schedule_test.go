@@ -0,0 +1,103 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"bytes"
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestSchedule(t *testing.T) {
+	Convey("Subject: Learning rate schedules", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+
+		Convey("Step decay should drop the rate every few epochs", func() {
+			s := StepDecay{Every: 10, Factor: 0.5}
+			So(s.Rate(1, 9, 0), ShouldEqual, 1.0)
+			So(s.Rate(1, 10, 0), ShouldEqual, 0.5)
+			So(s.Rate(1, 25, 0), ShouldEqual, 0.25)
+		})
+		Convey("Exponential decay should drop the rate every epoch", func() {
+			s := ExponentialDecay{Gamma: 0.9}
+			So(s.Rate(2, 0, 0), ShouldEqual, 2.0)
+			So(s.Rate(2, 3, 0), ShouldAlmostEqual, 2*0.729)
+		})
+		Convey("Cosine annealing should restart after each period", func() {
+			s := CosineRestarts{Period: 4, Mult: 2, Min: 0.1}
+			So(s.Rate(1, 0, 0), ShouldEqual, 1.0)
+			So(s.Rate(1, 2, 0), ShouldAlmostEqual, 0.55)
+			So(s.Rate(1, 4, 0), ShouldEqual, 1.0)
+			So(s.Rate(1, 8, 0), ShouldAlmostEqual, 0.55)
+			So(s.Rate(1, 12, 0), ShouldEqual, 1.0)
+		})
+		Convey("Warmup should rise to the rate of the schedule that follows", func() {
+			s := LinearWarmup{Steps: 3, Then: ExponentialDecay{Gamma: 0.5}}
+			So(s.Rate(1, 0, 0), ShouldEqual, 0.25)
+			So(s.Rate(1, 0, 2), ShouldEqual, 0.75)
+			So(s.Rate(1, 0, 3), ShouldEqual, 1.0)
+			So(s.Rate(1, 1, 4), ShouldEqual, 0.5)
+			So(LinearWarmup{Steps: 1}.Rate(1, 5, 5), ShouldEqual, 1.0)
+		})
+		Convey("Reduce on plateau should cut the rate when the loss stalls", func() {
+			s := &ReduceOnPlateau{Factor: 0.5, Patience: 2, Min: 0.2}
+			for _, loss := range []float64{1, 0.8, 0.9} {
+				s.Observe(loss)
+			}
+			So(s.Rate(1, 0, 0), ShouldEqual, 1.0)
+			s.Observe(0.85)
+			So(s.Rate(1, 0, 0), ShouldEqual, 0.5)
+			for i := 0; i < 6; i++ {
+				s.Observe(1)
+			}
+			So(s.Rate(1, 0, 0), ShouldEqual, 0.2)
+		})
+		Convey("The Trainer should follow the schedule and log the rate", func() {
+			net, _ := NewLayered(2, []LayerSpec{{Size: 4, Func: TANH}, {Size: 1, Func: SIGMOID}})
+			var buf bytes.Buffer
+			plateau := &ReduceOnPlateau{Factor: 0.5, Patience: 1}
+			trainer := &Trainer{LearningRate: 1, Epochs: 3, Schedule: plateau,
+				Validation: xorDataset(), Logger: log.New(&buf, "", 0)}
+			So(trainer.Train(net, xorDataset()), ShouldBeNil)
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			So(len(lines), ShouldEqual, 3)
+			So(lines[0], ShouldStartWith, "neural: epoch 0: rate 1, loss ")
+			So(lines[0], ShouldContainSubstring, "validation loss")
+			So(plateau.seen, ShouldBeTrue)
+		})
+		Convey("A validation set of the wrong shape should be rejected", func() {
+			net, _ := NewLayered(2, []LayerSpec{{Size: 1, Func: SIGMOID}})
+			var bad Dataset
+			bad.Add([]float64{0}, []float64{0})
+			So((&Trainer{Epochs: 1, Validation: bad}).Train(net, xorDataset()), ShouldEqual, ErrInputSize)
+		})
+	})
+}
@@ -0,0 +1,189 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSON form of a Network. Connections refer to nodes by their index.
+type networkJSON struct {
+	Nodes []nodeJSON `json:"nodes"`
+	Conns []connJSON `json:"conns"`
+
+	Transform    OutputTransform `json:"transform,omitempty"`
+	InputScaler  *Scaler         `json:"inputScaler,omitempty"`
+	OutputScaler *Scaler         `json:"outputScaler,omitempty"`
+	Integrator   Integrator      `json:"integrator,omitempty"`
+}
+
+// JSON form of a Node
+type nodeJSON struct {
+	Type NodeType `json:"type"`
+	Func FuncType `json:"func"`
+	Agg  AggType  `json:"agg,omitempty"`
+
+	Bias     float64  `json:"bias,omitempty"`
+	Response *float64 `json:"response,omitempty"` // Omitted when 1
+	Tau      *float64 `json:"tau,omitempty"`      // Omitted when 1
+}
+
+// JSON form of a Connection
+type connJSON struct {
+	From   int     `json:"from"`
+	To     int     `json:"to"`
+	Weight float64 `json:"weight"`
+
+	// Only for PlasticConnections, whose Weight is the current one
+	Plasticity *Plasticity `json:"plasticity,omitempty"`
+	Initial    float64     `json:"initial,omitempty"`
+
+	// Only for gated connections
+	Gater *int `json:"gater,omitempty"`
+}
+
+// MarshalJSON encodes the nodes and connections of the Network
+func (n *Network) MarshalJSON() ([]byte, error) {
+	nj := networkJSON{
+		Nodes: make([]nodeJSON, len(n.nodes)),
+		Conns: make([]connJSON, len(n.conns)),
+
+		Transform:    n.transform,
+		InputScaler:  n.inputScaler,
+		OutputScaler: n.outputScaler,
+		Integrator:   n.integrator,
+	}
+
+	index := make(map[Node]int, len(n.nodes))
+	for i, x := range n.nodes {
+		index[x] = i
+		nj.Nodes[i] = nodeJSON{Type: x.NodeType(), Func: x.FuncType(), Agg: x.AggType(), Bias: x.Bias()}
+		if r := x.Response(); r != 1 {
+			nj.Nodes[i].Response = &r
+		}
+		if tau := x.TimeConstant(); tau != 1 {
+			nj.Nodes[i].Tau = &tau
+		}
+	}
+
+	for i, c := range n.conns {
+		from, ok := index[c.From()]
+		if !ok {
+			return nil, fmt.Errorf("neural: connection %d starts outside the network", i)
+		}
+		to, ok := index[c.To()]
+		if !ok {
+			return nil, fmt.Errorf("neural: connection %d ends outside the network", i)
+		}
+		nj.Conns[i] = connJSON{From: from, To: to, Weight: c.Weight()}
+		if p, ok := c.(*PlasticConnection); ok {
+			plasticity := p.Plasticity()
+			nj.Conns[i].Plasticity, nj.Conns[i].Initial = &plasticity, p.InitialWeight()
+		}
+		if g := gaterOf(c); g != nil {
+			gater, ok := index[g]
+			if !ok {
+				return nil, fmt.Errorf("neural: connection %d is gated from outside the network", i)
+			}
+			nj.Conns[i].Gater = &gater
+		}
+	}
+
+	return json.Marshal(nj)
+}
+
+// UnmarshalJSON replaces the Network with the one encoded by MarshalJSON
+func (n *Network) UnmarshalJSON(data []byte) error {
+	var nj networkJSON
+	if err := json.Unmarshal(data, &nj); err != nil {
+		return err
+	}
+
+	if nj.Transform > LOG_SOFTMAX {
+		return fmt.Errorf("neural: unknown output transform %d", nj.Transform)
+	}
+	if nj.Integrator > RK4 {
+		return fmt.Errorf("neural: unknown integrator %d", nj.Integrator)
+	}
+
+	network := Network{transform: nj.Transform, integrator: nj.Integrator}
+	nodes := make([]Node, len(nj.Nodes))
+	for i, x := range nj.Nodes {
+		if x.Type > MODULATORY {
+			return fmt.Errorf("neural: node %d has unknown node type %d", i, x.Type)
+		}
+		if x.Agg > MAXABS {
+			return fmt.Errorf("neural: node %d has unknown aggregation type %d", i, x.Agg)
+		}
+		if nodes[i] = NewNodeAgg(x.Func, x.Type, x.Agg); nodes[i] == nil {
+			return fmt.Errorf("neural: node %d has unknown function type %d", i, x.Func)
+		}
+		nodes[i].SetBias(x.Bias)
+		if x.Response != nil {
+			nodes[i].SetResponse(*x.Response)
+		}
+		if x.Tau != nil {
+			if err := nodes[i].SetTimeConstant(*x.Tau); err != nil {
+				return fmt.Errorf("neural: node %d: %v", i, err)
+			}
+		}
+		network.AddNode(nodes[i])
+	}
+
+	for i, c := range nj.Conns {
+		if c.From < 0 || c.From >= len(nodes) || c.To < 0 || c.To >= len(nodes) {
+			return fmt.Errorf("neural: connection %d refers to a missing node", i)
+		}
+		if c.Gater != nil && (*c.Gater < 0 || *c.Gater >= len(nodes)) {
+			return fmt.Errorf("neural: connection %d is gated by a missing node", i)
+		}
+		switch {
+		case c.Gater != nil:
+			network.AddConnection(NewGatedConnection(nodes[c.From], nodes[c.To], nodes[*c.Gater], c.Weight))
+		case c.Plasticity != nil:
+			if c.Plasticity.Rule > ABCD {
+				return fmt.Errorf("neural: connection %d has unknown plasticity rule %d", i, c.Plasticity.Rule)
+			}
+			p := NewPlasticConnection(nodes[c.From], nodes[c.To], c.Initial, *c.Plasticity)
+			p.weight = c.Weight
+			network.AddConnection(p)
+		default:
+			network.AddConnection(NewConnection(nodes[c.From], nodes[c.To], c.Weight))
+		}
+	}
+
+	if err := network.SetInputScaler(nj.InputScaler); err != nil {
+		return err
+	}
+	if err := network.SetOutputScaler(nj.OutputScaler); err != nil {
+		return err
+	}
+
+	*n = network
+	return nil
+}
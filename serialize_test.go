@@ -0,0 +1,100 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"encoding/json"
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestSerialize(t *testing.T) {
+	Convey("Subject: Serialization", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+		net, _ := NewLayered(3, []LayerSpec{{Size: 4, Func: TANH, Agg: MAX}, {Size: 2, Func: SIGMOID}})
+		net.nodes[len(net.nodes)-1].SetAggType(MEDIAN)
+		net.nodes[len(net.nodes)-2].SetBias(0.25)
+		net.nodes[len(net.nodes)-2].SetResponse(0.0)
+		inputs := []float64{0.1, -0.5, 0.9}
+
+		Convey("Given a Network encoded as JSON", func() {
+			data, err := json.Marshal(net)
+			So(err, ShouldBeNil)
+
+			Convey("Decoding should produce the same structure", func() {
+				var copy Network
+				So(json.Unmarshal(data, &copy), ShouldBeNil)
+				So(len(copy.nodes), ShouldEqual, len(net.nodes))
+				So(len(copy.conns), ShouldEqual, len(net.conns))
+				So(copy.inputCount, ShouldEqual, 3)
+				So(copy.outputCount, ShouldEqual, 2)
+				for i := range net.nodes {
+					So(copy.nodes[i].NodeType(), ShouldEqual, net.nodes[i].NodeType())
+					So(copy.nodes[i].FuncType(), ShouldEqual, net.nodes[i].FuncType())
+					So(copy.nodes[i].AggType(), ShouldEqual, net.nodes[i].AggType())
+					So(copy.nodes[i].Bias(), ShouldEqual, net.nodes[i].Bias())
+					So(copy.nodes[i].Response(), ShouldEqual, net.nodes[i].Response())
+				}
+			})
+			Convey("Decoding should produce the same outputs", func() {
+				var copy Network
+				So(json.Unmarshal(data, &copy), ShouldBeNil)
+				So(copy.Activate(inputs), ShouldResemble, net.Activate(inputs))
+			})
+		})
+
+		Convey("Given invalid JSON", func() {
+			var copy Network
+			Convey("An unknown function type should fail", func() {
+				So(json.Unmarshal([]byte(`{"nodes":[{"type":1,"func":99}]}`), &copy), ShouldNotBeNil)
+			})
+			Convey("An unknown node type should fail", func() {
+				So(json.Unmarshal([]byte(`{"nodes":[{"type":99}]}`), &copy), ShouldNotBeNil)
+			})
+			Convey("An unknown aggregation type should fail", func() {
+				So(json.Unmarshal([]byte(`{"nodes":[{"type":1,"agg":99}]}`), &copy), ShouldNotBeNil)
+			})
+			Convey("An unknown plasticity rule should fail", func() {
+				So(json.Unmarshal([]byte(`{"nodes":[{"type":1},{"type":2}],"conns":[{"from":0,"to":1,"plasticity":{"rule":99}}]}`), &copy), ShouldNotBeNil)
+			})
+			Convey("A connection to a missing node should fail", func() {
+				So(json.Unmarshal([]byte(`{"nodes":[{"type":1}],"conns":[{"from":0,"to":3}]}`), &copy), ShouldNotBeNil)
+			})
+			Convey("An unknown output transform should fail", func() {
+				So(json.Unmarshal([]byte(`{"nodes":[{"type":1}],"transform":99}`), &copy), ShouldNotBeNil)
+			})
+			Convey("An unknown integrator should fail", func() {
+				So(json.Unmarshal([]byte(`{"nodes":[{"type":1}],"integrator":99}`), &copy), ShouldNotBeNil)
+			})
+			Convey("A scaler with mismatched or zero scales should fail", func() {
+				So(json.Unmarshal([]byte(`{"nodes":[{"type":1}],"inputScaler":{"shift":[0],"scale":[]}}`), &copy), ShouldNotBeNil)
+				So(json.Unmarshal([]byte(`{"nodes":[{"type":1}],"inputScaler":{"shift":[0],"scale":[0]}}`), &copy), ShouldNotBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,196 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"errors"
+	"log"
+	"math/rand"
+)
+
+// Trainer trains a Network by gradient descent on a Dataset
+type Trainer struct {
+	LearningRate float64 // Size of each step against the mean gradient of a batch
+	Epochs       int     // Number of passes through the Dataset
+	BatchSize    int     // Samples per step. Zero means the whole Dataset
+	Loss         Loss    // Loss to minimize
+
+	// Regularization added to the loss. The max-norm constraint, if any, is
+	// applied after every step.
+	Regularization Regularization
+
+	// Seed for shuffling the samples before each epoch. Zero means no
+	// shuffling: every epoch sees the samples in their Dataset order.
+	Seed int64
+
+	// Optimizer turns the gradients into changes to the Network. Defaults to
+	// SGD.
+	Optimizer Optimizer
+
+	// Schedule, if set, varies the learning rate from LearningRate over the
+	// course of training
+	Schedule Schedule
+
+	// Validation, if not empty, is scored at the end of each epoch. A
+	// PlateauObserver schedule watches this loss rather than the training loss.
+	Validation Dataset
+
+	// Logger, if set, receives the learning rate and losses after each epoch.
+	// The training loss is averaged over the steps of the epoch.
+	Logger *log.Logger
+}
+
+// Train adjusts the Network to reduce the loss on the Dataset. The Network's
+// connections must be in activation order, see SortConnections.
+func (t *Trainer) Train(n *Network, ds Dataset) error {
+	if err := ds.check(n); err != nil {
+		return err
+	}
+	if ds.Len() == 0 {
+		return errors.New("neural: cannot train on an empty dataset")
+	}
+	if err := t.Validation.check(n); err != nil {
+		return err
+	}
+
+	batchSize := t.BatchSize
+	if batchSize <= 0 || batchSize > ds.Len() {
+		batchSize = ds.Len()
+	}
+	if _, ok := t.Optimizer.(*RPROP); ok && batchSize < ds.Len() {
+		return errors.New("neural: RPROP needs the whole dataset in each batch")
+	}
+
+	var rng *rand.Rand
+	if t.Seed != 0 {
+		rng = rand.New(rand.NewSource(t.Seed))
+	}
+
+	optimizer := t.Optimizer
+	if optimizer == nil {
+		optimizer = SGD{}
+	}
+
+	grads := n.NewGradients()
+	step := 0
+	for epoch := 0; epoch < t.Epochs; epoch++ {
+		epochSet := ds
+		if rng != nil {
+			epochSet = ds.Subset(rng.Perm(ds.Len()))
+		}
+
+		total, rate := 0.0, t.LearningRate
+		for start := 0; start < epochSet.Len(); start += batchSize {
+			end := start + batchSize
+			if end > epochSet.Len() {
+				end = epochSet.Len()
+			}
+
+			grads.Reset()
+			batchLoss := 0.0
+			for i := start; i < end; i++ {
+				batchLoss += n.BackpropLoss(t.Loss, epochSet.Inputs[i], epochSet.Targets[i], grads)
+			}
+			total += batchLoss
+			grads.Scale(1 / float64(end-start))
+			t.Regularization.AddGradients(n, grads, 1)
+
+			rate = t.rate(epoch, step)
+			optimizer.Step(n, grads, rate, batchLoss/float64(end-start)+t.Regularization.Penalty(n))
+			t.Regularization.Constrain(n)
+			step++
+		}
+
+		if err := t.endEpoch(n, epoch, rate, total/float64(ds.Len())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the learning rate for a step
+func (t *Trainer) rate(epoch, step int) float64 {
+	if t.Schedule == nil {
+		return t.LearningRate
+	}
+	return t.Schedule.Rate(t.LearningRate, epoch, step)
+}
+
+// Scores the validation set, if any, and passes the losses of the epoch just
+// finished to the schedule and the logger
+func (t *Trainer) endEpoch(n *Network, epoch int, rate, trainLoss float64) error {
+	trainLoss += t.Regularization.Penalty(n)
+	watched := trainLoss
+	if t.Validation.Len() > 0 {
+		validationLoss, err := t.Objective(n, t.Validation)
+		if err != nil {
+			return err
+		}
+		watched = validationLoss
+		if t.Logger != nil {
+			t.Logger.Printf("neural: epoch %d: rate %g, loss %g, validation loss %g", epoch, rate, trainLoss, validationLoss)
+		}
+	} else if t.Logger != nil {
+		t.Logger.Printf("neural: epoch %d: rate %g, loss %g", epoch, rate, trainLoss)
+	}
+
+	if p, ok := t.Schedule.(PlateauObserver); ok {
+		p.Observe(watched)
+	}
+	return nil
+}
+
+// Objective returns the loss the Trainer minimizes: the mean loss of the
+// Network over the Dataset plus the regularization penalty
+func (t *Trainer) Objective(n *Network, ds Dataset) (float64, error) {
+	loss, err := n.Evaluate(ds, t.Loss)
+	if err != nil {
+		return 0, err
+	}
+	return loss + t.Regularization.Penalty(n), nil
+}
+
+// Evaluate returns the mean loss of the Network over the Dataset, measured as
+// BackpropLoss measures it: against targets passed through any output scaler
+func (n *Network) Evaluate(ds Dataset, loss Loss) (float64, error) {
+	if err := ds.check(n); err != nil {
+		return 0, err
+	}
+	if ds.Len() == 0 {
+		return 0, nil
+	}
+
+	total := 0.0
+	outputs := make([]float64, n.outputCount)
+	errs := make([]float64, n.outputCount)
+	for i := range ds.Inputs {
+		n.forward(ds.Inputs[i], outputs)
+		transformOutputs(n.transform, outputs)
+		total += lossGradient(loss, n.transform, outputs, n.scaleTargets(ds.Targets[i]), errs)
+	}
+	return total / float64(ds.Len()), nil
+}
@@ -0,0 +1,71 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+// Returns the XOR problem as a Dataset
+func xorDataset() Dataset {
+	var ds Dataset
+	ds.Add([]float64{0, 0}, []float64{0})
+	ds.Add([]float64{0, 1}, []float64{1})
+	ds.Add([]float64{1, 0}, []float64{1})
+	ds.Add([]float64{1, 1}, []float64{0})
+	return ds
+}
+
+func TestTrainer(t *testing.T) {
+	Convey("Subject: Trainer", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+		net, _ := NewLayered(2, []LayerSpec{{Size: 4, Func: TANH}, {Size: 1, Func: SIGMOID}})
+		ds := xorDataset()
+
+		Convey("Training should reduce the loss", func() {
+			before, err := net.Evaluate(ds, SQUARED_ERROR)
+			So(err, ShouldBeNil)
+			trainer := &Trainer{LearningRate: 2, Epochs: 2000, BatchSize: 2, Seed: 1}
+			So(trainer.Train(net, ds), ShouldBeNil)
+			after, _ := net.Evaluate(ds, SQUARED_ERROR)
+			So(after, ShouldBeLessThan, before)
+			So(after, ShouldBeLessThan, 0.01)
+		})
+		Convey("A Dataset of the wrong shape should be rejected", func() {
+			var bad Dataset
+			bad.Add([]float64{0, 0, 0}, []float64{0})
+			So((&Trainer{Epochs: 1}).Train(net, bad), ShouldEqual, ErrInputSize)
+			_, err := net.Evaluate(bad, SQUARED_ERROR)
+			So(err, ShouldEqual, ErrInputSize)
+		})
+		Convey("An empty Dataset should be rejected", func() {
+			So((&Trainer{Epochs: 1}).Train(net, Dataset{}), ShouldNotBeNil)
+		})
+	})
+}
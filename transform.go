@@ -0,0 +1,96 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"math"
+)
+
+// OutputTransform identifies a function applied to the outputs of a Network as
+// a whole, after the output nodes have been activated
+type OutputTransform byte
+
+// Constants for OutputTransforms
+const (
+	NO_TRANSFORM OutputTransform = iota
+	SOFTMAX
+	LOG_SOFTMAX
+)
+
+// SetOutputTransform chooses the function applied to the outputs of the Network.
+// SOFTMAX turns the outputs into probabilities which sum to 1, and LOG_SOFTMAX
+// returns their logarithms. The default is NO_TRANSFORM.
+func (n *Network) SetOutputTransform(transform OutputTransform) {
+	n.transform = transform
+}
+
+// OutputTransform returns the function applied to the outputs of the Network
+func (n *Network) OutputTransform() OutputTransform {
+	return n.transform
+}
+
+// Applies the transform to outputs in place
+func transformOutputs[T Float](transform OutputTransform, outputs []T) {
+	if len(outputs) == 0 {
+		return
+	}
+
+	switch transform {
+	case SOFTMAX:
+		max := maxOf(outputs)
+		sum := 0.0
+		for i, x := range outputs {
+			e := math.Exp(float64(x - max))
+			outputs[i] = T(e)
+			sum += e
+		}
+		for i := range outputs {
+			outputs[i] = T(float64(outputs[i]) / sum)
+		}
+	case LOG_SOFTMAX:
+		max := maxOf(outputs)
+		sum := 0.0
+		for _, x := range outputs {
+			sum += math.Exp(float64(x - max))
+		}
+		shift := max + T(math.Log(sum))
+		for i := range outputs {
+			outputs[i] -= shift
+		}
+	}
+}
+
+// Returns the largest of values
+func maxOf[T Float](values []T) T {
+	max := values[0]
+	for _, x := range values[1:] {
+		if x > max {
+			max = x
+		}
+	}
+	return max
+}
@@ -0,0 +1,123 @@
+/*  Copyright (c) 2013, Brian Hummer (brian@boggo.net)
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+    * Redistributions of source code must retain the above copyright
+      notice, this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright
+      notice, this list of conditions and the following disclaimer in the
+      documentation and/or other materials provided with the distribution.
+    * Neither the name of the boggo.net nor the
+      names of its contributors may be used to endorse or promote products
+      derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL BRIAN HUMMER BE LIABLE FOR ANY
+DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package neural
+
+import (
+	"encoding/json"
+	"github.com/boggo/random"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+func TestOutputTransform(t *testing.T) {
+	Convey("Subject: Output Transform", t, func() {
+		random.Reseed(0) // Get a predictable random number generation
+		net, _ := NewLayered(3, []LayerSpec{{Size: 5, Func: TANH}, {Size: 4, Func: DIRECT}})
+		inputs := []float64{0.5, -1.5, 2.0}
+		raw := net.Activate(inputs)
+
+		Convey("SOFTMAX outputs should be probabilities", func() {
+			net.SetOutputTransform(SOFTMAX)
+			outputs := net.Activate(inputs)
+			sum := 0.0
+			for i, x := range outputs {
+				So(x, ShouldBeBetween, 0.0, 1.0)
+				sum += x
+				So(math.Log(x/outputs[0]), ShouldAlmostEqual, raw[i]-raw[0], 1e-12)
+			}
+			So(sum, ShouldAlmostEqual, 1.0, 1e-12)
+		})
+		Convey("LOG_SOFTMAX outputs should be the logarithm of SOFTMAX", func() {
+			net.SetOutputTransform(SOFTMAX)
+			probs := net.Activate(inputs)
+			net.SetOutputTransform(LOG_SOFTMAX)
+			for i, x := range net.Activate(inputs) {
+				So(x, ShouldAlmostEqual, math.Log(probs[i]), 1e-12)
+			}
+		})
+		Convey("Plans should apply the transform", func() {
+			net.SetOutputTransform(SOFTMAX)
+			plan, _ := net.Compile()
+			So(plan.Activate(inputs), ShouldResemble, net.Activate(inputs))
+			batch, _ := plan.ActivateBatch([][]float64{inputs, inputs})
+			So(batch[1], ShouldResemble, net.Activate(inputs))
+		})
+		Convey("The transform should be serialized", func() {
+			net.SetOutputTransform(LOG_SOFTMAX)
+			data, _ := json.Marshal(net)
+			var copy Network
+			So(json.Unmarshal(data, &copy), ShouldBeNil)
+			So(copy.OutputTransform(), ShouldEqual, LOG_SOFTMAX)
+		})
+
+		Convey("Gradients should match finite differences", func() {
+			targets := []float64{0, 1, 0, 0}
+			for _, transform := range []OutputTransform{NO_TRANSFORM, SOFTMAX, LOG_SOFTMAX} {
+				for _, loss := range []Loss{SQUARED_ERROR, CROSS_ENTROPY} {
+					if loss == CROSS_ENTROPY && transform == NO_TRANSFORM {
+						continue // DIRECT outputs are not probabilities
+					}
+					net.SetOutputTransform(transform)
+					grads := net.NewGradients()
+					So(net.BackpropLoss(loss, inputs, targets, grads), ShouldAlmostEqual, lossOf(net, loss, inputs, targets), 1e-12)
+					for i, c := range net.conns {
+						So(grads.Weights[i], ShouldAlmostEqual, numericLossSlope(net, loss, inputs, targets, c), 1e-6)
+					}
+				}
+			}
+		})
+		Convey("Cross entropy on sigmoid outputs should match finite differences", func() {
+			sig, _ := NewLayered(3, []LayerSpec{{Size: 4, Func: TANH}, {Size: 2, Func: SIGMOID}})
+			targets := []float64{1, 0}
+			grads := sig.NewGradients()
+			sig.BackpropLoss(CROSS_ENTROPY, inputs, targets, grads)
+			for i, c := range sig.conns {
+				So(grads.Weights[i], ShouldAlmostEqual, numericLossSlope(sig, CROSS_ENTROPY, inputs, targets, c), 1e-6)
+			}
+		})
+	})
+}
+
+// Returns the loss of the Network's transformed outputs for one sample
+func lossOf(net *Network, loss Loss, inputs, targets []float64) float64 {
+	outputs := net.Activate(inputs)
+	return lossGradient(loss, net.transform, outputs, targets, make([]float64, len(outputs)))
+}
+
+// Estimates the derivative of the loss with respect to the weight of c by
+// central differences
+func numericLossSlope(net *Network, loss Loss, inputs, targets []float64, c Connection) float64 {
+	const h = 1e-6
+	w := c.Weight()
+	c.SetWeight(w + h)
+	up := lossOf(net, loss, inputs, targets)
+	c.SetWeight(w - h)
+	down := lossOf(net, loss, inputs, targets)
+	c.SetWeight(w)
+	return (up - down) / (2 * h)
+}